@@ -1,9 +1,25 @@
 package brickDb
 
-import "github.com/seboste/sapper/ports"
+import (
+	"io"
+	"time"
+
+	"github.com/seboste/sapper/ports"
+)
 
 type AggregateBrickDB struct {
-	dbs []ports.BrickDB
+	dbs     []ports.BrickDB
+	remotes []string         //names of the remotes of the dbs. Empty for remotes that are not subject to pins
+	pins    []ports.BrickPin //pins that refer to one of the remotes
+}
+
+// provides returns false if the brick is pinned to another remote than the one of the i-th db
+func (abdb AggregateBrickDB) provides(i int, brickId string) bool {
+	if i >= len(abdb.remotes) || abdb.remotes[i] == "" {
+		return true
+	}
+	pin, ok := ports.MatchingPin(abdb.pins, brickId)
+	return !ok || pin.Remote == abdb.remotes[i]
 }
 
 func contains(bricks []ports.Brick, b ports.Brick) bool {
@@ -17,9 +33,9 @@ func contains(bricks []ports.Brick, b ports.Brick) bool {
 
 func (abdb AggregateBrickDB) Bricks(k ports.BrickKind) []ports.Brick {
 	bricks := []ports.Brick{}
-	for _, db := range abdb.dbs {
+	for i, db := range abdb.dbs {
 		for _, b := range db.Bricks(k) {
-			if !contains(bricks, b) {
+			if abdb.provides(i, b.Id) && !contains(bricks, b) {
 				bricks = append(bricks, b)
 			}
 		}
@@ -28,24 +44,80 @@ func (abdb AggregateBrickDB) Bricks(k ports.BrickKind) []ports.Brick {
 }
 
 func (abdb AggregateBrickDB) Brick(id string) (ports.Brick, error) {
-	for _, db := range abdb.dbs {
+	notFound := ports.BrickNotFound //the first error that tells more than that the brick has not been found
+	for i, db := range abdb.dbs {
+		if !abdb.provides(i, id) {
+			continue
+		}
 		brick, err := db.Brick(id)
 		if err == nil {
 			return brick, nil
 		}
+		if notFound == ports.BrickNotFound {
+			notFound = err
+		}
 	}
-	return ports.Brick{}, ports.BrickNotFound
+	return ports.Brick{}, notFound
 }
 
-func (abdb AggregateBrickDB) Update() error {
+func (abdb AggregateBrickDB) BrickVersions(id string) []ports.Brick {
+	for i, db := range abdb.dbs {
+		if !abdb.provides(i, id) {
+			continue
+		}
+		if versions := db.BrickVersions(id); len(versions) > 0 {
+			return versions
+		}
+	}
+	return []ports.Brick{}
+}
+
+func (abdb AggregateBrickDB) BrickMatching(id string, constraint ports.VersionConstraint) (ports.Brick, error) {
+	notFound := ports.BrickNotFound //the first error that tells more than that the brick has not been found
+	for i, db := range abdb.dbs {
+		if !abdb.provides(i, id) {
+			continue
+		}
+		//like Brick and BrickVersions only the first remote that provides the brick is considered
+		brick, err := db.BrickMatching(id, constraint)
+		if err == nil || len(db.BrickVersions(id)) > 0 {
+			return brick, err
+		}
+		if notFound == ports.BrickNotFound {
+			notFound = err
+		}
+	}
+	return ports.Brick{}, notFound
+}
+
+func (abdb AggregateBrickDB) InvalidManifests() []ports.BrickManifestError {
+	invalidManifests := []ports.BrickManifestError{}
+	for _, db := range abdb.dbs {
+		invalidManifests = append(invalidManifests, db.InvalidManifests()...)
+	}
+	return invalidManifests
+}
+
+func (abdb AggregateBrickDB) Update(output io.Writer) error {
 	for _, db := range abdb.dbs {
-		if err := db.Update(); err != nil {
+		if err := db.Update(output); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// LastUpdate returns the oldest update of all brick DBs
+func (abdb AggregateBrickDB) LastUpdate() time.Time {
+	lastUpdate := time.Time{}
+	for i, db := range abdb.dbs {
+		if t := db.LastUpdate(); i == 0 || t.Before(lastUpdate) {
+			lastUpdate = t
+		}
+	}
+	return lastUpdate
+}
+
 func (abdb AggregateBrickDB) IsModified() (bool, string) {
 	modified := false
 	details := ""
@@ -58,4 +130,8 @@ func (abdb AggregateBrickDB) IsModified() (bool, string) {
 	return modified, details
 }
 
-var _ ports.BrickDB = AggregateBrickDB{}
+func (abdb AggregateBrickDB) RemoteBrickDBs() []ports.BrickDB {
+	return abdb.dbs
+}
+
+var _ ports.AggregatedBrickDB = AggregateBrickDB{}
@@ -1,8 +1,10 @@
 package brickDb
 
 import (
+	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/seboste/sapper/ports"
 )
@@ -26,10 +28,39 @@ func (db MockBrickDB) Brick(id string) (ports.Brick, error) {
 	return ports.Brick{}, ports.BrickNotFound
 }
 
-func (db MockBrickDB) Update() error {
+func (db MockBrickDB) BrickVersions(id string) []ports.Brick {
+	versions := []ports.Brick{}
+	for _, bricks := range db.BricksMap {
+		for _, brick := range bricks {
+			if brick.Id == id {
+				versions = append(versions, brick)
+			}
+		}
+	}
+	return versions
+}
+
+func (db MockBrickDB) BrickMatching(id string, constraint ports.VersionConstraint) (ports.Brick, error) {
+	for _, brick := range db.BrickVersions(id) {
+		if constraint.Matches(brick.Version) {
+			return brick, nil
+		}
+	}
+	return ports.Brick{}, ports.BrickNotFound
+}
+
+func (db MockBrickDB) Update(output io.Writer) error {
 	return nil
 }
 
+func (db MockBrickDB) InvalidManifests() []ports.BrickManifestError {
+	return nil
+}
+
+func (db MockBrickDB) LastUpdate() time.Time {
+	return time.Time{}
+}
+
 func (db MockBrickDB) IsModified() (bool, string) {
 	return false, ""
 }
@@ -117,3 +148,70 @@ func TestAggregateBrickDB_Brick(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregateBrickDB_Pins(t *testing.T) {
+	db1 := MockBrickDB{BricksMap: map[ports.BrickKind][]ports.Brick{ports.Extension: {{Id: "ExtensionA", Version: "1.0.0"}, {Id: "ExtensionB", Version: "1.0.0"}}}}
+	db2 := MockBrickDB{BricksMap: map[ports.BrickKind][]ports.Brick{ports.Extension: {{Id: "ExtensionA", Version: "2.0.0"}}}}
+	vendored := MockBrickDB{BricksMap: map[ports.BrickKind][]ports.Brick{ports.Extension: {{Id: "ExtensionA", Version: "0.1.0"}}}}
+
+	tests := []struct {
+		name    string
+		abdb    AggregateBrickDB
+		id      string
+		want    ports.Brick
+		wantErr bool
+	}{
+		{name: "pinned to second remote", abdb: AggregateBrickDB{dbs: []ports.BrickDB{db1, db2}, remotes: []string{"one", "two"}, pins: []ports.BrickPin{{Brick: "ExtensionA", Remote: "two"}}}, id: "ExtensionA", want: ports.Brick{Id: "ExtensionA", Version: "2.0.0"}},
+		{name: "glob pin", abdb: AggregateBrickDB{dbs: []ports.BrickDB{db1, db2}, remotes: []string{"one", "two"}, pins: []ports.BrickPin{{Brick: "Extension*", Remote: "two"}}}, id: "ExtensionA", want: ports.Brick{Id: "ExtensionA", Version: "2.0.0"}},
+		{name: "pinned remote does not provide brick", abdb: AggregateBrickDB{dbs: []ports.BrickDB{db1, db2}, remotes: []string{"one", "two"}, pins: []ports.BrickPin{{Brick: "ExtensionB", Remote: "two"}}}, id: "ExtensionB", wantErr: true},
+		{name: "other brick not affected", abdb: AggregateBrickDB{dbs: []ports.BrickDB{db1, db2}, remotes: []string{"one", "two"}, pins: []ports.BrickPin{{Brick: "ExtensionA", Remote: "two"}}}, id: "ExtensionB", want: ports.Brick{Id: "ExtensionB", Version: "1.0.0"}},
+		{name: "unconfigured remote is not subject to pins", abdb: AggregateBrickDB{dbs: []ports.BrickDB{vendored, db1, db2}, remotes: []string{"", "one", "two"}, pins: []ports.BrickPin{{Brick: "ExtensionA", Remote: "two"}}}, id: "ExtensionA", want: ports.Brick{Id: "ExtensionA", Version: "0.1.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.abdb.Brick(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AggregateBrickDB.Brick() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AggregateBrickDB.Brick() = %v, want %v", got, tt.want)
+			}
+			if versions := tt.abdb.BrickVersions(tt.id); !tt.wantErr && !reflect.DeepEqual(versions, []ports.Brick{tt.want}) {
+				t.Errorf("AggregateBrickDB.BrickVersions() = %v, want [%v]", versions, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregateBrickDB_BrickMatching(t *testing.T) {
+	db1 := MockBrickDB{BricksMap: map[ports.BrickKind][]ports.Brick{ports.Extension: {{Id: "ExtensionA", Version: "1.0.0"}}}}
+	db2 := MockBrickDB{BricksMap: map[ports.BrickKind][]ports.Brick{ports.Extension: {{Id: "ExtensionA", Version: "2.0.0"}, {Id: "ExtensionB", Version: "2.0.0"}}}}
+	abdb := AggregateBrickDB{dbs: []ports.BrickDB{db1, db2}}
+
+	tests := []struct {
+		name       string
+		id         string
+		constraint string
+		want       ports.Brick
+		wantErr    bool
+	}{
+		{name: "first remote", id: "ExtensionA", constraint: "^1.0.0", want: ports.Brick{Id: "ExtensionA", Version: "1.0.0"}},
+		{name: "shadowed version is not considered", id: "ExtensionA", constraint: "^2.0.0", wantErr: true},
+		{name: "second remote", id: "ExtensionB", constraint: "^2.0.0", want: ports.Brick{Id: "ExtensionB", Version: "2.0.0"}},
+		{name: "unknown brick", id: "ExtensionC", constraint: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, _ := ports.ParseVersionConstraint(tt.constraint)
+			got, err := abdb.BrickMatching(tt.id, constraint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AggregateBrickDB.BrickMatching() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AggregateBrickDB.BrickMatching() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,7 +10,11 @@ func makeBrickDB(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
 
 	switch r.Kind {
 	case ports.GitRemote:
-		gbdb, err := MakeGitBrickDB(filepath.Join(remotesDir, r.Name), r.Src)
+		path := ports.CloneDir(remotesDir, r.Src)
+		if err := adoptLegacyClone(filepath.Join(remotesDir, r.Name), path, r.Src); err != nil {
+			return nil, err
+		}
+		gbdb, err := MakeGitBrickDB(path, r.Src)
 		return &gbdb, err
 
 	case ports.FilesystemRemote:
@@ -22,21 +26,47 @@ func makeBrickDB(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
 }
 
 type Factory struct {
-	//Remote
+	Configuration ports.Configuration //provides the pins. Optional
 }
 
 func (f Factory) MakeBrickDB(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
 	return makeBrickDB(r, remotesDir)
 }
 
+// MakeAggregatedBrickDB creates a brick DB that takes each brick from the first remote providing it. Bricks that are pinned
+// to a configured remote are not taken from other configured remotes. Remotes that are not configured (e.g. the vendored bricks
+// of a service) are not subject to pins.
 func (f Factory) MakeAggregatedBrickDB(remotes []ports.Remote, remotesDir string) (ports.BrickDB, error) {
 	abdb := AggregateBrickDB{}
+	configured := map[ports.Remote]bool{}
+	if f.Configuration != nil {
+		for _, r := range f.Configuration.Remotes() {
+			configured[r] = true
+		}
+	}
+
 	for _, r := range remotes {
 		db, err := makeBrickDB(r, remotesDir)
 		if err != nil {
 			return abdb, err
 		}
 		abdb.dbs = append(abdb.dbs, db)
+		if configured[r] {
+			abdb.remotes = append(abdb.remotes, r.Name)
+		} else {
+			abdb.remotes = append(abdb.remotes, "")
+		}
+	}
+
+	if f.Configuration != nil {
+		for _, p := range f.Configuration.Pins() {
+			for _, name := range abdb.remotes {
+				if name != "" && name == p.Remote {
+					abdb.pins = append(abdb.pins, p)
+					break
+				}
+			}
+		}
 	}
 	return abdb, nil
 }
@@ -1,9 +1,13 @@
 package brickDb
 
 import (
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/seboste/sapper/ports"
 	"gopkg.in/yaml.v3"
@@ -12,11 +16,12 @@ import (
 func makeBrick(path string) (ports.Brick, error) {
 	b := ports.Brick{}
 
-	yamlFile, err := ioutil.ReadFile(filepath.Join(path, "manifest.yaml"))
+	manifestPath := filepath.Join(path, "manifest.yaml")
+	yamlFile, err := ioutil.ReadFile(manifestPath)
 	if err != nil {
 		return ports.Brick(b), err
 	}
-	err = yaml.Unmarshal(yamlFile, &b)
+	b, err = parseManifest(manifestPath, yamlFile)
 	if err != nil {
 		return b, err
 	}
@@ -43,11 +48,25 @@ func makeBrick(path string) (ports.Brick, error) {
 			return nil
 		})
 
-	return b, nil
+	return b, err
+}
+
+// manifestId returns the id of a brick even if its manifest is invalid otherwise
+func manifestId(manifestPath string) string {
+	yamlFile, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return ""
+	}
+	manifest := struct {
+		Id string `yaml:"id"`
+	}{}
+	yaml.Unmarshal(yamlFile, &manifest)
+	return manifest.Id
 }
 
 type FilesystemBrickDB struct {
-	bricks []ports.Brick
+	bricks           []ports.Brick
+	invalidManifests []ports.BrickManifestError
 }
 
 func MakeFilesystemBrickDB(basePath string) (FilesystemBrickDB, error) {
@@ -63,7 +82,9 @@ func MakeFilesystemBrickDB(basePath string) (FilesystemBrickDB, error) {
 			if file == "manifest.yaml" {
 				brick, err := makeBrick(dir)
 				if err != nil {
-					return err
+					//a broken brick must not render the other bricks unusable
+					db.invalidManifests = append(db.invalidManifests, ports.BrickManifestError{Path: path, Id: manifestId(path), Err: err})
+					return nil
 				}
 
 				db.bricks = append(db.bricks, brick)
@@ -75,28 +96,80 @@ func MakeFilesystemBrickDB(basePath string) (FilesystemBrickDB, error) {
 }
 
 func (db *FilesystemBrickDB) Bricks(kind ports.BrickKind) []ports.Brick {
-	filteredBricks := []ports.Brick{}
+	//find the highest version of each brick in a single pass. Like Brick, the last one wins among equal versions
+	ids := []string{}
+	highest := map[string]ports.Brick{}
 	for _, b := range db.bricks {
-		if b.Kind == kind {
-			filteredBricks = append(filteredBricks, b)
+		h, ok := highest[b.Id]
+		if !ok {
+			ids = append(ids, b.Id)
+		}
+		if !ok || !ports.LessVersion(b.Version, h.Version) {
+			highest[b.Id] = b
+		}
+	}
+
+	filteredBricks := []ports.Brick{}
+	for _, id := range ids {
+		if highest[id].Kind == kind {
+			filteredBricks = append(filteredBricks, highest[id])
 		}
 	}
 	return filteredBricks
 }
 
 func (db *FilesystemBrickDB) Brick(id string) (ports.Brick, error) {
+	versions := db.BrickVersions(id)
+	if len(versions) == 0 {
+		return ports.Brick{}, db.notFound(id)
+	}
+	return versions[len(versions)-1], nil
+}
+
+func (db *FilesystemBrickDB) BrickVersions(id string) []ports.Brick {
+	versions := []ports.Brick{}
 	for _, b := range db.bricks {
 		if b.Id == id {
-			return b, nil
+			versions = append(versions, b)
 		}
 	}
-	return ports.Brick{}, ports.BrickNotFound
+	sort.SliceStable(versions, func(i, j int) bool { return ports.LessVersion(versions[i].Version, versions[j].Version) })
+	return versions
 }
 
-func (db *FilesystemBrickDB) Update() error {
+func (db *FilesystemBrickDB) BrickMatching(id string, constraint ports.VersionConstraint) (ports.Brick, error) {
+	versions := db.BrickVersions(id)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if constraint.Matches(versions[i].Version) {
+			return versions[i], nil
+		}
+	}
+	return ports.Brick{}, db.notFound(id)
+}
+
+// notFound returns the error for a brick that could not be found. The error mentions the invalid manifests of the brick.
+func (db *FilesystemBrickDB) notFound(id string) error {
+	for _, invalidManifest := range db.invalidManifests {
+		if invalidManifest.Id == id {
+			return fmt.Errorf("%w: skipped invalid manifest %v", ports.BrickNotFound, invalidManifest.Err)
+		}
+	}
+	return ports.BrickNotFound
+}
+
+func (db *FilesystemBrickDB) InvalidManifests() []ports.BrickManifestError {
+	return db.invalidManifests
+}
+
+func (db *FilesystemBrickDB) Update(output io.Writer) error {
 	return nil
 }
 
+// LastUpdate returns the current time as the bricks are read directly from their source
+func (db FilesystemBrickDB) LastUpdate() time.Time {
+	return time.Now()
+}
+
 func (db FilesystemBrickDB) IsModified() (bool, string) {
 	return false, "unable to identify"
 }
@@ -1,11 +1,13 @@
 package brickDb
 
 import (
+	"errors"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/seboste/sapper/ports"
@@ -115,6 +117,11 @@ func TestFilesystemBrickDB_Bricks(t *testing.T) {
 			args:   args{kind: ports.Helper},
 			want:   []ports.Brick{brickHelper},
 		},
+		{name: "highest version only",
+			fields: fields{bricks: []ports.Brick{{Id: "ext", Version: "1.0.0", Kind: ports.Extension}, {Id: "ext", Version: "2.0.0", Kind: ports.Extension}}},
+			args:   args{kind: ports.Extension},
+			want:   []ports.Brick{{Id: "ext", Version: "2.0.0", Kind: ports.Extension}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -156,6 +163,12 @@ func TestFilesystemBrickDB_Brick(t *testing.T) {
 			wantErr: true,
 			want:    ports.Brick{},
 		},
+		{name: "highest version",
+			fields:  fields{bricks: []ports.Brick{{Id: "some_id", Version: "1.10.0"}, {Id: "some_id", Version: "2.0.0"}, {Id: "some_id", Version: "1.9.0"}}},
+			args:    args{id: "some_id"},
+			wantErr: false,
+			want:    ports.Brick{Id: "some_id", Version: "2.0.0"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -175,6 +188,65 @@ func TestFilesystemBrickDB_Brick(t *testing.T) {
 	}
 }
 
+func TestFilesystemBrickDB_BrickVersions(t *testing.T) {
+	v1 := ports.Brick{Id: "some_id", Version: "1.0.0"}
+	v2 := ports.Brick{Id: "some_id", Version: "1.10.0"}
+	v3 := ports.Brick{Id: "some_id", Version: "2.0.0"}
+	other := ports.Brick{Id: "some_other_id", Version: "1.5.0"}
+	db := &FilesystemBrickDB{bricks: []ports.Brick{v3, other, v1, v2}}
+
+	tests := []struct {
+		name string
+		id   string
+		want []ports.Brick
+	}{
+		{name: "ascending order", id: "some_id", want: []ports.Brick{v1, v2, v3}},
+		{name: "single version", id: "some_other_id", want: []ports.Brick{other}},
+		{name: "unknown", id: "some_unknown_id", want: []ports.Brick{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.BrickVersions(tt.id); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilesystemBrickDB.BrickVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilesystemBrickDB_BrickMatching(t *testing.T) {
+	v1 := ports.Brick{Id: "some_id", Version: "1.0.0"}
+	v2 := ports.Brick{Id: "some_id", Version: "1.10.0"}
+	v3 := ports.Brick{Id: "some_id", Version: "2.0.0"}
+	db := &FilesystemBrickDB{bricks: []ports.Brick{v3, v1, v2}}
+
+	tests := []struct {
+		name       string
+		id         string
+		constraint string
+		want       ports.Brick
+		wantErr    bool
+	}{
+		{name: "any version", id: "some_id", constraint: "", want: v3},
+		{name: "major version 1", id: "some_id", constraint: "^1.0.0", want: v2},
+		{name: "exact version", id: "some_id", constraint: "1.0.0", want: v1},
+		{name: "no match", id: "some_id", constraint: "^3.0.0", want: ports.Brick{}, wantErr: true},
+		{name: "unknown id", id: "some_unknown_id", constraint: "", want: ports.Brick{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, _ := ports.ParseVersionConstraint(tt.constraint)
+			got, err := db.BrickMatching(tt.id, constraint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FilesystemBrickDB.BrickMatching() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilesystemBrickDB.BrickMatching() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMakeFilesystemBrickDB(t *testing.T) {
 
 	tempDir, _ := ioutil.TempDir("", "example_db")
@@ -233,3 +305,38 @@ description : test brick 2
 		})
 	}
 }
+
+func TestMakeFilesystemBrickDB_InvalidManifest(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "invalid_db")
+	defer os.RemoveAll(tempDir) // clean up
+
+	os.Mkdir(filepath.Join(tempDir, "valid"), 0777)
+	ioutil.WriteFile(filepath.Join(tempDir, "valid", "manifest.yaml"), []byte("id: valid\nkind: extension\n"), 0666)
+	os.Mkdir(filepath.Join(tempDir, "invalid"), 0777)
+	ioutil.WriteFile(filepath.Join(tempDir, "invalid", "manifest.yaml"), []byte("id: invalid\nkind: something_invalid\n"), 0666)
+
+	db, err := MakeFilesystemBrickDB(tempDir)
+	if err != nil {
+		t.Errorf("MakeFilesystemBrickDB() error = %v", err)
+	}
+	if want := []ports.Brick{{Id: "valid", Kind: ports.Extension, BasePath: filepath.Join(tempDir, "valid")}}; !reflect.DeepEqual(db.bricks, want) {
+		t.Errorf("FilesystemBrickDB.bricks = %v, want %v", db.bricks, want)
+	}
+	invalidManifests := db.InvalidManifests()
+	if len(invalidManifests) != 1 || invalidManifests[0].Path != filepath.Join(tempDir, "invalid", "manifest.yaml") {
+		t.Errorf("FilesystemBrickDB.InvalidManifests() = %v, want a single entry for the invalid brick", invalidManifests)
+	}
+
+	//looking up the invalid brick reports why it has been skipped
+	_, err = db.Brick("invalid")
+	if !errors.Is(err, ports.BrickNotFound) || !strings.Contains(err.Error(), "something_invalid") {
+		t.Errorf("FilesystemBrickDB.Brick() error = %v, want the manifest error", err)
+	}
+	_, err = AggregateBrickDB{dbs: []ports.BrickDB{&FilesystemBrickDB{}, &db}}.BrickMatching("invalid", ports.VersionConstraint{})
+	if !errors.Is(err, ports.BrickNotFound) || !strings.Contains(err.Error(), "something_invalid") {
+		t.Errorf("AggregateBrickDB.BrickMatching() error = %v, want the manifest error", err)
+	}
+	if _, err = db.Brick("unknown"); err != ports.BrickNotFound {
+		t.Errorf("FilesystemBrickDB.Brick() error = %v, want %v", err, ports.BrickNotFound)
+	}
+}
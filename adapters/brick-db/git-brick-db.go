@@ -3,10 +3,15 @@ package brickDb
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/seboste/sapper/ports"
+	"github.com/seboste/sapper/utils"
 )
 
 type GitBrickDB struct {
@@ -23,13 +28,38 @@ func (gbdb GitBrickDB) Clone() error {
 	return err
 }
 
-func (gbdb GitBrickDB) Update() error {
+// lock prevents other sapper processes from cloning or pulling the remote at the same time
+func (gbdb GitBrickDB) lock() (func(), error) {
+	return utils.LockFile(filepath.Clean(gbdb.Path)+".lock", os.Stderr)
+}
+
+// Update pulls the latest version and writes the output of git to output
+func (gbdb GitBrickDB) Update(output io.Writer) error {
+	unlock, err := gbdb.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	cmd := exec.Command("git", "pull")
 	cmd.Dir = gbdb.Path
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	err := cmd.Run()
-	return err
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git pull in %s failed: %v", gbdb.Path, err)
+	}
+	return nil
+}
+
+// LastUpdate returns the time of the last fetch or, if the remote has never been fetched, of the clone
+func (gbdb GitBrickDB) LastUpdate() time.Time {
+	lastUpdate := time.Time{}
+	for _, file := range []string{"FETCH_HEAD", "HEAD"} {
+		if info, err := os.Stat(filepath.Join(gbdb.Path, ".git", file)); err == nil && info.ModTime().After(lastUpdate) {
+			lastUpdate = info.ModTime()
+		}
+	}
+	return lastUpdate
 }
 
 func (gbdb GitBrickDB) IsModified() (bool, string) {
@@ -46,13 +76,41 @@ func (gbdb GitBrickDB) IsModified() (bool, string) {
 	return changes != "", details
 }
 
+// adoptLegacyClone moves a clone of url from a folder named after its remote (as created by earlier versions of sapper) to path
+func adoptLegacyClone(legacyPath string, path string, url string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(legacyPath, ".git")); err != nil {
+		return nil
+	}
+	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd.Dir = legacyPath
+	if origin, err := cmd.Output(); err != nil || strings.TrimSpace(string(origin)) != url {
+		return nil
+	}
+	if err := os.Rename(legacyPath, path); err != nil {
+		if _, statErr := os.Stat(path); statErr == nil { //adopted by another process in the meantime
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
 func MakeGitBrickDB(path string, url string) (GitBrickDB, error) {
 	db := GitBrickDB{Path: path, Url: url}
 	if _, err := os.Stat(db.Path); os.IsNotExist(err) {
-		err = db.Clone()
+		unlock, err := db.lock()
 		if err != nil {
 			return db, err
 		}
+		defer unlock()
+		if _, err := os.Stat(db.Path); os.IsNotExist(err) { //another process may have cloned in the meantime
+			if err := db.Clone(); err != nil {
+				return db, err
+			}
+		}
 	}
 	var err error
 	db.FilesystemBrickDB, err = MakeFilesystemBrickDB(db.Path)
@@ -0,0 +1,318 @@
+package brickDb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentManifestVersion is the manifest format version understood by this version of sapper.
+// Manifests without a manifestVersion are treated as version 1.
+const CurrentManifestVersion = 1
+
+type schemaType string
+
+const (
+	objectType  schemaType = "object"
+	arrayType   schemaType = "array"
+	stringType  schemaType = "string"
+	integerType schemaType = "integer"
+	booleanType schemaType = "boolean"
+)
+
+type schemaProperty struct {
+	name     string
+	required bool
+	schema   schemaNode
+}
+
+// schemaNode is a minimal subset of JSON Schema that is sufficient to describe a manifest.yaml
+type schemaNode struct {
+	typ         schemaType
+	description string
+	enum        []string
+	minimum     *int
+	properties  []schemaProperty //only for objects
+	items       *schemaNode      //only for arrays
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func brickKindNames() []string {
+	names := []string{}
+	for _, k := range ports.BrickKinds {
+		names = append(names, k.String())
+	}
+	return names
+}
+
+var manifestSchema = schemaNode{
+	typ:         objectType,
+	description: "Manifest of a sapper brick",
+	properties: []schemaProperty{
+		{name: "manifestVersion", schema: schemaNode{typ: integerType, minimum: intPtr(1), description: "Version of the manifest format"}},
+		{name: "id", required: true, schema: schemaNode{typ: stringType, description: "Unique identifier of the brick"}},
+		{name: "description", schema: schemaNode{typ: stringType, description: "Brief description of the brick"}},
+		{name: "version", schema: schemaNode{typ: stringType, description: "Semantic version of the brick"}},
+		{name: "kind", schema: schemaNode{typ: stringType, enum: brickKindNames(), description: "Kind of the brick (default: template)"}},
+		{name: "parameters", schema: schemaNode{typ: arrayType, description: "Parameters that are replaced in the brick's files", items: &schemaNode{
+			typ: objectType,
+			properties: []schemaProperty{
+				{name: "name", required: true, schema: schemaNode{typ: stringType, description: "Name of the parameter"}},
+				{name: "default", schema: schemaNode{typ: stringType, description: "Default value of the parameter"}},
+				{name: "description", schema: schemaNode{typ: stringType, description: "Brief description of the parameter that is shown when its value is requested"}},
+				{name: "choices", schema: schemaNode{typ: arrayType, description: "Allowed values of the parameter", items: &schemaNode{typ: stringType}}},
+				{name: "pattern", schema: schemaNode{typ: stringType, description: "Regular expression the value of the parameter must match completely"}},
+				{name: "secret", schema: schemaNode{typ: booleanType, description: "Hides the value of the parameter while it is entered and keeps it out of the sapperfile"}},
+			},
+		}}},
+		{name: "dependencies", schema: schemaNode{typ: arrayType, description: "Ids of the bricks this brick depends on, optionally with a version constraint (id@constraint)", items: &schemaNode{typ: stringType}}},
+	},
+}
+
+// ManifestError lists all problems of a manifest.yaml along with their position
+type ManifestError struct {
+	Path     string
+	Problems []string
+}
+
+func (e ManifestError) Error() string {
+	lines := []string{}
+	for _, p := range e.Problems {
+		lines = append(lines, e.Path+":"+p)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func position(node *yaml.Node) string {
+	return fmt.Sprintf("%d:%d", node.Line, node.Column)
+}
+
+func kindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	if node.Tag == "!!null" {
+		return "null"
+	}
+	return "scalar"
+}
+
+func (s schemaNode) validate(node *yaml.Node, path string) []string {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	problems := []string{}
+	switch s.typ {
+	case objectType:
+		if node.Kind != yaml.MappingNode {
+			return []string{fmt.Sprintf("%s: %s must be an object, got %s", position(node), path, kindName(node))}
+		}
+		found := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if found[key.Value] {
+				problems = append(problems, fmt.Sprintf("%s: duplicate field '%s' in %s", position(key), key.Value, path))
+				continue
+			}
+			found[key.Value] = true
+
+			p, ok := s.property(key.Value)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown field '%s' in %s", position(key), key.Value, path))
+				continue
+			}
+			problems = append(problems, p.schema.validate(value, path+"."+key.Value)...)
+		}
+		for _, p := range s.properties {
+			if p.required && !found[p.name] {
+				problems = append(problems, fmt.Sprintf("%s: missing required field '%s' in %s", position(node), p.name, path))
+			}
+		}
+	case arrayType:
+		if node.Kind != yaml.SequenceNode {
+			return []string{fmt.Sprintf("%s: %s must be an array, got %s", position(node), path, kindName(node))}
+		}
+		for i, item := range node.Content {
+			problems = append(problems, s.items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case stringType:
+		if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+			return []string{fmt.Sprintf("%s: %s must be a string, got %s", position(node), path, kindName(node))}
+		}
+		if len(s.enum) > 0 && !containsFold(s.enum, node.Value) {
+			problems = append(problems, fmt.Sprintf("%s: invalid value '%s' for %s. Must be one of %s", position(node), node.Value, path, strings.Join(s.enum, ", ")))
+		}
+	case booleanType:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return []string{fmt.Sprintf("%s: %s must be a boolean, got %s", position(node), path, kindName(node))}
+		}
+	case integerType:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return []string{fmt.Sprintf("%s: %s must be an integer, got %s", position(node), path, kindName(node))}
+		}
+		var i int
+		if err := node.Decode(&i); err != nil {
+			return []string{fmt.Sprintf("%s: %s must be an integer: %v", position(node), path, err)}
+		}
+		if s.minimum != nil && i < *s.minimum {
+			problems = append(problems, fmt.Sprintf("%s: %s must be at least %d", position(node), path, *s.minimum))
+		}
+	}
+	return problems
+}
+
+func (s schemaNode) property(name string) (schemaProperty, bool) {
+	for _, p := range s.properties {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return schemaProperty{}, false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s schemaNode) jsonSchema() map[string]interface{} {
+	js := map[string]interface{}{"type": string(s.typ)}
+	if s.description != "" {
+		js["description"] = s.description
+	}
+	if len(s.enum) > 0 {
+		js["enum"] = s.enum
+	}
+	if s.minimum != nil {
+		js["minimum"] = *s.minimum
+	}
+	if s.typ == objectType {
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, p := range s.properties {
+			properties[p.name] = p.schema.jsonSchema()
+			if p.required {
+				required = append(required, p.name)
+			}
+		}
+		sort.Strings(required)
+		js["properties"] = properties
+		js["additionalProperties"] = false
+		if len(required) > 0 {
+			js["required"] = required
+		}
+	}
+	if s.items != nil {
+		js["items"] = s.items.jsonSchema()
+	}
+	return js
+}
+
+// parseManifest strictly decodes the content of a manifest.yaml. All problems are reported with line and column.
+func parseManifest(path string, data []byte) (ports.Brick, error) {
+	b := ports.Brick{}
+
+	root := yaml.Node{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return b, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return b, ManifestError{Path: path, Problems: []string{"1:1: manifest is empty"}}
+	}
+	doc := root.Content[0]
+
+	if problems := manifestSchema.validate(doc, "manifest"); len(problems) > 0 {
+		return b, ManifestError{Path: path, Problems: problems}
+	}
+
+	if err := doc.Decode(&b); err != nil {
+		return b, fmt.Errorf("%s: %w", path, err)
+	}
+	if b.ManifestVersion > CurrentManifestVersion {
+		return b, fmt.Errorf("%s: unsupported manifestVersion %d. This version of sapper supports manifests up to version %d", path, b.ManifestVersion, CurrentManifestVersion)
+	}
+	for _, p := range b.Parameters {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return b, fmt.Errorf("%s: invalid pattern '%s' of parameter %s: %v", path, p.Pattern, p.Name, err)
+		}
+		if p.Default != "" {
+			if err := p.Validate(p.Default); err != nil {
+				return b, fmt.Errorf("%s: default value: %w", path, err)
+			}
+		}
+	}
+	// migrations of older manifest versions go here once the format changes
+	return b, nil
+}
+
+// ManifestSchema exports the schema of a brick's manifest.yaml
+type ManifestSchema struct{}
+
+func (ManifestSchema) JSONSchema() ([]byte, error) {
+	js := manifestSchema.jsonSchema()
+	js["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	js["title"] = "Sapper brick manifest"
+	return json.MarshalIndent(js, "", "  ")
+}
+
+type manifestParameter struct {
+	Name        string   `yaml:"name"`
+	Default     string   `yaml:"default,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Choices     []string `yaml:"choices,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+	Secret      bool     `yaml:"secret,omitempty"`
+}
+
+type manifest struct {
+	ManifestVersion int                 `yaml:"manifestVersion"`
+	Id              string              `yaml:"id"`
+	Description     string              `yaml:"description,omitempty"`
+	Version         string              `yaml:"version,omitempty"`
+	Kind            string              `yaml:"kind"`
+	Parameters      []manifestParameter `yaml:"parameters,omitempty"`
+	Dependencies    []string            `yaml:"dependencies,omitempty"`
+}
+
+// ManifestWriter writes a brick's manifest.yaml in the current manifest version
+type ManifestWriter struct{}
+
+func (ManifestWriter) WriteManifest(b ports.Brick, dir string) error {
+	m := manifest{
+		ManifestVersion: CurrentManifestVersion,
+		Id:              b.Id,
+		Description:     b.Description,
+		Version:         b.Version,
+		Kind:            b.Kind.String(),
+		Dependencies:    b.Dependencies,
+	}
+	for _, p := range b.Parameters {
+		m.Parameters = append(m.Parameters, manifestParameter{Name: p.Name, Default: p.Default, Description: p.Description, Choices: p.Choices, Pattern: p.Pattern, Secret: p.Secret})
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "manifest.yaml"), data, 0644)
+}
@@ -0,0 +1,169 @@
+package brickDb
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func Test_parseManifest(t *testing.T) {
+	tests := []struct {
+		name         string
+		yaml         string
+		want         ports.Brick
+		wantProblems []string
+		wantErr      bool
+	}{
+		{name: "valid manifest",
+			yaml: "manifestVersion: 1\nid: test\nkind: Extension\nversion: 1.0.0\nparameters:\n - name: p1\n   default: d1\n   description: the p1\ndependencies:\n - dep1@^1.0.0\n",
+			want: ports.Brick{ManifestVersion: 1, Id: "test", Kind: ports.Extension, Version: "1.0.0", Parameters: []ports.BrickParameters{{Name: "p1", Default: "d1", Description: "the p1"}}, Dependencies: []string{"dep1@^1.0.0"}},
+		},
+		{name: "parameters with choices, pattern, and secret",
+			yaml: "id: test\nparameters:\n - name: DB\n   default: postgres\n   choices: [postgres, mysql]\n - name: PORT\n   pattern: '[0-9]+'\n - name: TOKEN\n   secret: true\n",
+			want: ports.Brick{Id: "test", Parameters: []ports.BrickParameters{{Name: "DB", Default: "postgres", Choices: []string{"postgres", "mysql"}}, {Name: "PORT", Pattern: "[0-9]+"}, {Name: "TOKEN", Secret: true}}},
+		},
+		{name: "secret is not a boolean",
+			yaml:         "id: test\nparameters:\n - name: TOKEN\n   secret: maybe\n",
+			wantProblems: []string{"4:12: manifest.parameters[0].secret must be a boolean, got scalar"},
+			wantErr:      true,
+		},
+		{name: "invalid pattern",
+			yaml:    "id: test\nparameters:\n - name: PORT\n   pattern: '[0-9'\n",
+			wantErr: true,
+		},
+		{name: "default is not one of the choices",
+			yaml:    "id: test\nparameters:\n - name: DB\n   default: sqlite\n   choices: [postgres, mysql]\n",
+			wantErr: true,
+		},
+		{name: "without manifest version",
+			yaml: "id: test\n",
+			want: ports.Brick{Id: "test"},
+		},
+		{name: "unknown field",
+			yaml:         "id: test\nkind: extension\nfoo: bar\n",
+			wantProblems: []string{"3:1: unknown field 'foo' in manifest"},
+			wantErr:      true,
+		},
+		{name: "derived fields are not part of the manifest",
+			yaml:         "id: test\nbasepath: /tmp\n",
+			wantProblems: []string{"2:1: unknown field 'basepath' in manifest"},
+			wantErr:      true,
+		},
+		{name: "missing id",
+			yaml:         "kind: extension\n",
+			wantProblems: []string{"1:1: missing required field 'id' in manifest"},
+			wantErr:      true,
+		},
+		{name: "invalid kind",
+			yaml:         "id: test\nkind: something_invalid\n",
+			wantProblems: []string{"2:7: invalid value 'something_invalid' for manifest.kind. Must be one of template, extension, helper"},
+			wantErr:      true,
+		},
+		{name: "several problems in nested fields",
+			yaml:         "id: test\nparameters:\n - default: d1\n - name: p2\n   hidden: true\ndependencies: dep1\n",
+			wantProblems: []string{"3:4: missing required field 'name' in manifest.parameters[0]", "5:4: unknown field 'hidden' in manifest.parameters[1]", "6:15: manifest.dependencies must be an array, got scalar"},
+			wantErr:      true,
+		},
+		{name: "duplicate field",
+			yaml:         "id: test\nid: other\n",
+			wantProblems: []string{"2:1: duplicate field 'id' in manifest"},
+			wantErr:      true,
+		},
+		{name: "manifest version is not an integer",
+			yaml:         "id: test\nmanifestVersion: one\n",
+			wantProblems: []string{"2:18: manifest.manifestVersion must be an integer, got scalar"},
+			wantErr:      true,
+		},
+		{name: "unsupported manifest version",
+			yaml:    "id: test\nmanifestVersion: 2\n",
+			wantErr: true,
+		},
+		{name: "syntax error",
+			yaml:    "id: test\n  kind: extension\n",
+			wantErr: true,
+		},
+		{name: "empty manifest",
+			yaml:         "",
+			wantProblems: []string{"1:1: manifest is empty"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseManifest("manifest.yaml", []byte(tt.yaml))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseManifest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantProblems != nil {
+				manifestErr, ok := err.(ManifestError)
+				if !ok {
+					t.Errorf("parseManifest() error = %v, want a ManifestError", err)
+					return
+				}
+				if !reflect.DeepEqual(manifestErr.Problems, tt.wantProblems) {
+					t.Errorf("parseManifest() problems = %v, want %v", manifestErr.Problems, tt.wantProblems)
+				}
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseManifest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestSchema_JSONSchema(t *testing.T) {
+	data, err := ManifestSchema{}.JSONSchema()
+	if err != nil {
+		t.Fatalf("ManifestSchema.JSONSchema() error = %v", err)
+	}
+	schema := map[string]interface{}{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("ManifestSchema.JSONSchema() returned invalid JSON: %v", err)
+	}
+	if schema["additionalProperties"] != false {
+		t.Errorf("ManifestSchema.JSONSchema() must not allow additional properties")
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"manifestVersion", "id", "description", "version", "kind", "parameters", "dependencies"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("ManifestSchema.JSONSchema() is missing property %s", name)
+		}
+	}
+	if !reflect.DeepEqual(schema["required"], []interface{}{"id"}) {
+		t.Errorf("ManifestSchema.JSONSchema() required = %v, want [id]", schema["required"])
+	}
+}
+
+func TestManifestWriter_WriteManifest(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "manifest_writer")
+	defer os.RemoveAll(tempDir) // clean up
+
+	b := ports.Brick{
+		Id:           "test",
+		Description:  "My test brick",
+		Version:      "0.1.0",
+		Kind:         ports.Extension,
+		Parameters:   []ports.BrickParameters{{Name: "p1", Default: "d1"}, {Name: "p2"}},
+		Dependencies: []string{"dep1"},
+	}
+	if err := (ManifestWriter{}).WriteManifest(b, tempDir); err != nil {
+		t.Fatalf("ManifestWriter.WriteManifest() error = %v", err)
+	}
+
+	manifestPath := filepath.Join(tempDir, "manifest.yaml")
+	data, _ := ioutil.ReadFile(manifestPath)
+	got, err := parseManifest(manifestPath, data)
+	if err != nil {
+		t.Fatalf("written manifest is invalid: %v", err)
+	}
+	b.ManifestVersion = CurrentManifestVersion
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("ManifestWriter.WriteManifest() wrote %v, want %v", got, b)
+	}
+}
@@ -0,0 +1,117 @@
+package brickPackager
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type TarGzBrickPackager struct {
+}
+
+func addFile(tw *tar.Writer, basePath string, file string) error {
+	path := filepath.Join(basePath, file)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(file)
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func (p TarGzBrickPackager) Pack(b ports.Brick, packagePath string) error {
+	f, err := os.Create(packagePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	for _, file := range append([]string{"manifest.yaml"}, b.Files...) {
+		if err := addFile(tw, b.BasePath, file); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func extractFile(tr *tar.Reader, header *tar.Header, targetDir string) error {
+	path := filepath.Join(targetDir, filepath.FromSlash(header.Name))
+	if rel, err := filepath.Rel(targetDir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("invalid file %s in brick package", header.Name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, header.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, tr)
+	return err
+}
+
+func (p TarGzBrickPackager) Unpack(packagePath string, targetDir string) error {
+	f, err := os.Open(packagePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue //bricks only consist of regular files
+		}
+		if err := extractFile(tr, header, targetDir); err != nil {
+			return err
+		}
+	}
+}
+
+var _ ports.BrickPackager = TarGzBrickPackager{}
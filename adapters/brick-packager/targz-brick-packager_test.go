@@ -0,0 +1,72 @@
+package brickPackager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func TestTarGzBrickPackager_PackUnpack(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "packagerTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	tests := []struct {
+		name    string
+		files   map[string]string //filename -> content
+		brick   ports.Brick
+		wantErr bool
+	}{
+		{name: "manifest only",
+			files: map[string]string{"manifest.yaml": "id: brick_1"},
+			brick: ports.Brick{Id: "brick_1"}, wantErr: false},
+		{name: "nested files",
+			files: map[string]string{"manifest.yaml": "id: brick_2", "a": "content a", "b/c": "content c"},
+			brick: ports.Brick{Id: "brick_2", Files: []string{"a", "b/c"}}, wantErr: false},
+		{name: "missing file",
+			files: map[string]string{"manifest.yaml": "id: brick_3"},
+			brick: ports.Brick{Id: "brick_3", Files: []string{"missing"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//1. prepare test
+			tt.brick.BasePath = filepath.Join(tempDir, tt.brick.Id)
+			for file, content := range tt.files {
+				path := filepath.Join(tt.brick.BasePath, file)
+				os.MkdirAll(filepath.Dir(path), 0777)
+				ioutil.WriteFile(path, []byte(content), 0666)
+			}
+			packagePath := filepath.Join(tempDir, tt.brick.Id+".tar.gz")
+			targetDir := filepath.Join(tempDir, tt.brick.Id+"_unpacked")
+
+			//2. execute test
+			p := TarGzBrickPackager{}
+			err := p.Pack(tt.brick, packagePath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TarGzBrickPackager.Pack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := p.Unpack(packagePath, targetDir); err != nil {
+				t.Errorf("TarGzBrickPackager.Unpack() error = %v", err)
+			}
+
+			gotFiles := map[string]string{}
+			filepath.Walk(targetDir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					relPath, _ := filepath.Rel(targetDir, path)
+					content, _ := ioutil.ReadFile(path)
+					gotFiles[filepath.ToSlash(relPath)] = string(content)
+				}
+				return nil
+			})
+			if !reflect.DeepEqual(gotFiles, tt.files) {
+				t.Errorf("TarGzBrickPackager unpacked files = %v, want %v", gotFiles, tt.files)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seboste/sapper/ports"
+)
+
+// keys of the configuration that can be accessed by ConfigurationEditor. Remotes, pins, and parameters are accessed by
+// e.g. Remotes.<remote name>.
+const (
+	homeKey            = "Home"
+	configFileKey      = "ConfigFile"
+	projectFileKey     = "ProjectFile"
+	activeProfileKey   = "ActiveProfile"
+	remoteUpdateTTLKey = "RemoteUpdateTTL"
+	remotesKey         = "Remotes"
+	pinsKey            = "Pins"
+	parametersKey      = "Parameters"
+)
+
+var parameterNameExp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// splitKey splits a dotted key into its section and the name within the section (e.g. Parameters.PORT)
+func splitKey(key string) (section string, name string) {
+	section, name, _ = strings.Cut(key, ".")
+	return section, name
+}
+
+func (fsc FileSystemConfiguration) Values() []ports.ConfigurationValue {
+	homeSource := ports.DefaultConfiguration
+	if os.Getenv(HomeEnv) != "" {
+		homeSource = ports.EnvConfiguration
+	}
+	configFileSource := ports.DefaultConfiguration
+	if fsc.File != "" {
+		configFileSource = ports.FlagConfiguration
+	}
+	profileSource := ports.DefaultConfiguration
+	if fsc.Override != "" {
+		profileSource = ports.FlagConfiguration
+	} else if fsc.Active != "" {
+		profileSource = ports.UserConfiguration
+	}
+
+	values := []ports.ConfigurationValue{
+		{Key: homeKey, Value: fsc.Path, Source: homeSource},
+		{Key: configFileKey, Value: fsc.ConfigPath(), Source: configFileSource},
+	}
+	if fsc.Project != nil {
+		values = append(values, ports.ConfigurationValue{Key: projectFileKey, Value: fsc.Project.Path, Source: ports.ProjectConfiguration})
+	}
+	values = append(values, ports.ConfigurationValue{Key: activeProfileKey, Value: fsc.ActiveProfile(), Source: profileSource})
+
+	p := fsc.profile(fsc.ActiveProfile())
+	if fsc.Project != nil && fsc.Project.UpdateTTL != "" {
+		values = append(values, ports.ConfigurationValue{Key: remoteUpdateTTLKey, Value: fsc.Project.UpdateTTL, Source: ports.ProjectConfiguration})
+	} else if p.UpdateTTL != "" {
+		values = append(values, ports.ConfigurationValue{Key: remoteUpdateTTLKey, Value: p.UpdateTTL, Source: ports.UserConfiguration})
+	}
+
+	for _, r := range fsc.Remotes() {
+		source := ports.UserConfiguration
+		if fsc.Project != nil && fsc.Project.containsRemote(r) {
+			source = ports.ProjectConfiguration
+		}
+		values = append(values, ports.ConfigurationValue{Key: remotesKey + "." + r.Name, Value: r.Src, Source: source})
+	}
+
+	for _, pin := range fsc.Pins() {
+		source := ports.UserConfiguration
+		if fsc.Project != nil && fsc.Project.containsPin(pin) {
+			source = ports.ProjectConfiguration
+		}
+		values = append(values, ports.ConfigurationValue{Key: pinsKey + "." + pin.Brick, Value: pin.Remote, Source: source})
+	}
+
+	parameters := fsc.Parameters()
+	names := []string{}
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		source := ports.UserConfiguration
+		if fsc.Project != nil {
+			if _, ok := fsc.Project.Params[name]; ok {
+				source = ports.ProjectConfiguration
+			}
+		}
+		values = append(values, ports.ConfigurationValue{Key: parametersKey + "." + name, Value: parameters[name], Source: source})
+	}
+	return values
+}
+
+// readOnlyError explains how to change a value that cannot be set by SetValue
+func readOnlyError(key string) error {
+	switch section, _ := splitKey(key); section {
+	case homeKey:
+		return fmt.Errorf("%s is set by the %s environment variable", key, HomeEnv)
+	case configFileKey:
+		return fmt.Errorf("%s is set by the --config flag", key)
+	case projectFileKey:
+		return fmt.Errorf("%s is the %s found in the service folder or one of its parents", key, ProjectConfigurationFile)
+	case remotesKey:
+		return fmt.Errorf("%s is managed by 'sapper remote'", key)
+	case pinsKey:
+		return fmt.Errorf("%s is managed by 'sapper remote pin'", key)
+	}
+	return fmt.Errorf("unknown key %s. Valid keys are %s, %s, and %s.<PARAMETER_NAME>", key, activeProfileKey, remoteUpdateTTLKey, parametersKey)
+}
+
+// SetValue validates and sets a value of the active profile
+func (fsc *FileSystemConfiguration) SetValue(key string, value string) error {
+	if key == activeProfileKey {
+		return fsc.UseProfile(value)
+	}
+	if !fsc.HasProfile(fsc.ActiveProfile()) {
+		return fmt.Errorf("profile %s does not exist. Activate another profile first", fsc.ActiveProfile())
+	}
+	section, name := splitKey(key)
+	p := fsc.profile(fsc.ActiveProfile())
+	switch {
+	case key == remoteUpdateTTLKey:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid value %s of %s: %v", value, key, err)
+		}
+		if fsc.Project != nil && fsc.Project.UpdateTTL != "" {
+			return fmt.Errorf("%s is overridden by the project configuration %s", key, fsc.Project.Path)
+		}
+		p.UpdateTTL = value
+		fsc.setProfile(fsc.ActiveProfile(), p)
+		return nil
+	case section == parametersKey:
+		if !parameterNameExp.MatchString(name) {
+			return fmt.Errorf("invalid parameter name %s", name)
+		}
+		if value == "" {
+			return fmt.Errorf("value of %s must not be empty", key)
+		}
+		if fsc.Project != nil {
+			if projectValue, ok := fsc.Project.Params[name]; ok && projectValue != value {
+				return fmt.Errorf("%s is overridden by the project configuration %s", key, fsc.Project.Path)
+			}
+		}
+		if p.Params == nil {
+			p.Params = map[string]string{}
+		}
+		p.Params[name] = value
+		fsc.setProfile(fsc.ActiveProfile(), p)
+		return nil
+	}
+	return readOnlyError(key)
+}
+
+// UnsetValue removes a value of the active profile
+func (fsc *FileSystemConfiguration) UnsetValue(key string) error {
+	if key == activeProfileKey {
+		return fsc.UseProfile(DefaultProfile)
+	}
+	if !fsc.HasProfile(fsc.ActiveProfile()) {
+		return fmt.Errorf("profile %s does not exist. Activate another profile first", fsc.ActiveProfile())
+	}
+	section, name := splitKey(key)
+	p := fsc.profile(fsc.ActiveProfile())
+	switch {
+	case key == remoteUpdateTTLKey:
+		p.UpdateTTL = ""
+	case section == parametersKey:
+		if _, ok := p.Params[name]; !ok {
+			return fmt.Errorf("%s is not set in %s", key, fsc.ConfigPath())
+		}
+		params := map[string]string{}
+		for k, v := range p.Params {
+			if k != name {
+				params[k] = v
+			}
+		}
+		p.Params = params
+	default:
+		return readOnlyError(key)
+	}
+	fsc.setProfile(fsc.ActiveProfile(), p)
+	return nil
+}
+
+var _ ports.ConfigurationEditor = (*FileSystemConfiguration)(nil)
@@ -0,0 +1,96 @@
+package configuration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func TestFileSystemConfiguration_Values(t *testing.T) {
+	t.Setenv(HomeEnv, "")
+	fsc := FileSystemConfiguration{
+		Path:      "/home/me/.sapper",
+		Project:   &ProjectConfiguration{Path: "/repo/.sapper.yaml", Rmts: []ports.Remote{{Name: "repo", Src: "/repo/bricks"}}, Params: map[string]string{"PORT": "8081"}},
+		Rmts:      []ports.Remote{{Name: "sapper-bricks", Kind: ports.GitRemote, Src: "https://github.com/seboste/sapper-bricks.git"}},
+		BrickPins: []ports.BrickPin{{Brick: "handler-*", Remote: "sapper-bricks"}},
+		UpdateTTL: "24h",
+		Params:    map[string]string{"PORT": "8080", "REGISTRY": "registry.example.com"},
+		Override:  "default",
+	}
+	want := []ports.ConfigurationValue{
+		{Key: "Home", Value: "/home/me/.sapper", Source: ports.DefaultConfiguration},
+		{Key: "ConfigFile", Value: "/home/me/.sapper/config.yaml", Source: ports.DefaultConfiguration},
+		{Key: "ProjectFile", Value: "/repo/.sapper.yaml", Source: ports.ProjectConfiguration},
+		{Key: "ActiveProfile", Value: "default", Source: ports.FlagConfiguration},
+		{Key: "RemoteUpdateTTL", Value: "24h", Source: ports.UserConfiguration},
+		{Key: "Remotes.repo", Value: "/repo/bricks", Source: ports.ProjectConfiguration},
+		{Key: "Remotes.sapper-bricks", Value: "https://github.com/seboste/sapper-bricks.git", Source: ports.UserConfiguration},
+		{Key: "Pins.handler-*", Value: "sapper-bricks", Source: ports.UserConfiguration},
+		{Key: "Parameters.PORT", Value: "8081", Source: ports.ProjectConfiguration},
+		{Key: "Parameters.REGISTRY", Value: "registry.example.com", Source: ports.UserConfiguration},
+	}
+	if got := fsc.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemConfiguration.Values() = %v, want %v", got, want)
+	}
+}
+
+func TestFileSystemConfiguration_SetValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		project    *ProjectConfiguration
+		wantErr    bool
+		wantConfig FileSystemConfiguration
+	}{
+		{name: "remote update ttl", key: "RemoteUpdateTTL", value: "2h", wantConfig: FileSystemConfiguration{UpdateTTL: "2h", Profiles: map[string]Profile{"oss": {}}}},
+		{name: "invalid remote update ttl", key: "RemoteUpdateTTL", value: "soon", wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "parameter", key: "Parameters.PORT", value: "8080", wantConfig: FileSystemConfiguration{Params: map[string]string{"PORT": "8080"}, Profiles: map[string]Profile{"oss": {}}}},
+		{name: "invalid parameter name", key: "Parameters.1-2", value: "8080", wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "active profile", key: "ActiveProfile", value: "oss", wantConfig: FileSystemConfiguration{Active: "oss", Profiles: map[string]Profile{"oss": {}}}},
+		{name: "unknown profile", key: "ActiveProfile", value: "missing", wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "remotes are read only", key: "Remotes.local", value: "/bricks", wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "unknown key", key: "Foo", value: "bar", wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "ttl overridden by the project", key: "RemoteUpdateTTL", value: "2h", project: &ProjectConfiguration{UpdateTTL: "1h"}, wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "parameter overridden by the project", key: "Parameters.PORT", value: "8080", project: &ProjectConfiguration{Params: map[string]string{"PORT": "8081"}}, wantErr: true, wantConfig: FileSystemConfiguration{Profiles: map[string]Profile{"oss": {}}}},
+		{name: "parameter not set by the project", key: "Parameters.HOST", value: "localhost", project: &ProjectConfiguration{Params: map[string]string{"PORT": "8081"}}, wantConfig: FileSystemConfiguration{Params: map[string]string{"HOST": "localhost"}, Profiles: map[string]Profile{"oss": {}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsc := FileSystemConfiguration{Project: tt.project, Profiles: map[string]Profile{"oss": {}}}
+			if err := fsc.SetValue(tt.key, tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("FileSystemConfiguration.SetValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			fsc.Project = nil
+			if !reflect.DeepEqual(withoutSnapshot(fsc), tt.wantConfig) {
+				t.Errorf("FileSystemConfiguration.SetValue() config = %v, want %v", fsc, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestFileSystemConfiguration_UnsetValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		wantErr    bool
+		wantConfig FileSystemConfiguration
+	}{
+		{name: "remote update ttl", key: "RemoteUpdateTTL", wantConfig: FileSystemConfiguration{Params: map[string]string{"PORT": "8080"}}},
+		{name: "parameter", key: "Parameters.PORT", wantConfig: FileSystemConfiguration{UpdateTTL: "2h", Params: map[string]string{}}},
+		{name: "parameter not set", key: "Parameters.HOST", wantErr: true, wantConfig: FileSystemConfiguration{UpdateTTL: "2h", Params: map[string]string{"PORT": "8080"}}},
+		{name: "read only", key: "Home", wantErr: true, wantConfig: FileSystemConfiguration{UpdateTTL: "2h", Params: map[string]string{"PORT": "8080"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsc := FileSystemConfiguration{UpdateTTL: "2h", Params: map[string]string{"PORT": "8080"}}
+			if err := fsc.UnsetValue(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("FileSystemConfiguration.UnsetValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(withoutSnapshot(fsc), tt.wantConfig) {
+				t.Errorf("FileSystemConfiguration.UnsetValue() config = %v, want %v", fsc, tt.wantConfig)
+			}
+		})
+	}
+}
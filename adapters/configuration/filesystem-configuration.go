@@ -1,68 +1,193 @@
 package configuration
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/seboste/sapper/ports"
+	"github.com/seboste/sapper/utils"
 	"gopkg.in/yaml.v3"
 )
 
 type FileSystemConfiguration struct {
-	Path string         `yaml:"-"`
-	Rmts []ports.Remote `yaml:"Remotes"`
+	Path       string                `yaml:"-"` //sapper's home directory containing e.g. the clones of the git remotes
+	File       string                `yaml:"-"` //path of the config file. Defaults to config.yaml in the home directory
+	Project    *ProjectConfiguration `yaml:"-"` //optional project configuration that adds to or overrides this configuration
+	Version    int                   `yaml:"Version"`
+	Rmts       []ports.Remote        `yaml:"Remotes"`
+	BrickPins  []ports.BrickPin      `yaml:"Pins,omitempty"`
+	UpdateTTL  string                `yaml:"RemoteUpdateTTL,omitempty"` //e.g. 24h
+	Params     map[string]string     `yaml:"Parameters,omitempty"`
+	Profiles   map[string]Profile    `yaml:"Profiles,omitempty"`
+	Active     string                `yaml:"ActiveProfile,omitempty"` //name of the active profile. Empty for the default profile
+	Override   string                `yaml:"-"`                       //name of the profile that is used instead of the active profile (e.g. by --profile)
+	unreadable bool                  //the config file could not be read. Saving would lose its content.
+	loaded     *snapshot             //content of the config file when it has been loaded or saved. Used to merge the changes of other processes.
 }
 
+// Profile is a named set of remotes and defaults. The remotes, pins, and TTL at the top level of the configuration form the default profile.
+type Profile struct {
+	Rmts      []ports.Remote    `yaml:"Remotes"`
+	BrickPins []ports.BrickPin  `yaml:"Pins,omitempty"`
+	UpdateTTL string            `yaml:"RemoteUpdateTTL,omitempty"`
+	Params    map[string]string `yaml:"Parameters,omitempty"`
+}
+
+// DefaultProfile is the name of the profile formed by the top level of the configuration
+const DefaultProfile = "default"
+
 var defaultRemote ports.Remote = ports.Remote{
 	Name: "sapper-bricks",
 	Kind: ports.GitRemote,
 	Src:  "https://github.com/seboste/sapper-bricks.git",
 }
 
-var defaultConfiguration FileSystemConfiguration
+// HomeEnv is the environment variable that overrides sapper's home directory
+const HomeEnv = "SAPPER_HOME"
 
-func init() {
-	homeDir, err := os.UserHomeDir()
+// HomeDir returns sapper's home directory. This is $SAPPER_HOME if set and ~/.sapper otherwise.
+func HomeDir() (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return filepath.Abs(home)
+	}
+	userHomeDir, err := os.UserHomeDir()
 	if err != nil {
-		panic(err)
+		return "", err
 	}
-	defaultConfiguration.Path = filepath.Join(homeDir, ".sapper")
-	defaultConfiguration.Rmts = []ports.Remote{defaultRemote}
+	return filepath.Join(userHomeDir, ".sapper"), nil
 }
 
-func MakeFilesystemConfiguration() (FileSystemConfiguration, error) {
-	fsc := FileSystemConfiguration{Path: defaultConfiguration.Path}
-	err := fsc.Load()
-	if err != nil && os.IsNotExist(err) { //config does not exit => write default config and retry
-		err = defaultConfiguration.Save()
+func defaultConfiguration(homeDir string) FileSystemConfiguration {
+	return FileSystemConfiguration{Path: homeDir, Rmts: []ports.Remote{defaultRemote}}
+}
+
+// MakeFilesystemConfiguration loads the configuration from configFile or, if empty, from config.yaml in sapper's home directory.
+// The default configuration is written if the latter does not exist. The project configuration (.sapper.yaml) found in
+// projectDir or one of its parents is applied on top.
+// A non-empty profile is used instead of the active profile.
+func MakeFilesystemConfiguration(configFile string, profile string, projectDir string) (FileSystemConfiguration, error) {
+	homeDir, err := HomeDir()
+	if err != nil {
+		return FileSystemConfiguration{}, err
+	}
+
+	fsc := FileSystemConfiguration{Path: homeDir, File: configFile, Override: profile}
+	err = fsc.Load()
+	if err != nil && os.IsNotExist(err) && configFile == "" { //config does not exit => write default config and retry
+		defaultConfig := defaultConfiguration(homeDir)
+		err = defaultConfig.Save()
 		if err != nil {
 			return fsc, err
 		}
 		err = fsc.Load()
 	}
+	if err != nil {
+		return fsc, err
+	}
+
+	if projectFile, ok := FindProjectConfiguration(projectDir); ok {
+		project, err := LoadProjectConfiguration(projectFile)
+		if err != nil {
+			return fsc, err
+		}
+		fsc.Project = &project
+	}
 	return fsc, nil
 }
 
 func (fsc FileSystemConfiguration) ConfigPath() string {
+	if fsc.File != "" {
+		return fsc.File
+	}
 	return filepath.Join(fsc.Path, "config.yaml")
 }
 
+// decode migrates the content of a config file to the current version and decodes it into fsc. Unknown fields (e.g. typos) are
+// rejected. It returns whether the content has been migrated.
+func decode(data []byte, fsc *FileSystemConfiguration) (bool, error) {
+	migratedData, migrated, err := migrate(data)
+	if err != nil {
+		return false, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(migratedData))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(fsc); err != nil && err != io.EOF {
+		return false, err
+	}
+	if fsc.Version == 0 { //written before the format was versioned
+		fsc.Version = 1
+	}
+	return migrated, nil
+}
+
 func (fsc *FileSystemConfiguration) Load() error {
 	yamlFile, err := ioutil.ReadFile(fsc.ConfigPath())
+	fsc.unreadable = err != nil && !os.IsNotExist(err)
 	if err != nil {
 		return err
 	}
-	err = yaml.Unmarshal(yamlFile, fsc)
+	migrated, err := decode(yamlFile, fsc)
+	if err != nil {
+		fsc.unreadable = true
+		return fmt.Errorf("invalid configuration %s: %v", fsc.ConfigPath(), err)
+	}
+	loaded, err := fsc.snapshot()
 	if err != nil {
 		return err
 	}
+	fsc.loaded = &loaded
+	if fsc.UpdateTTL != "" {
+		if _, err := time.ParseDuration(fsc.UpdateTTL); err != nil {
+			return fmt.Errorf("invalid RemoteUpdateTTL %s in %s: %v", fsc.UpdateTTL, fsc.ConfigPath(), err)
+		}
+	}
+	for name, p := range fsc.Profiles {
+		if p.UpdateTTL == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(p.UpdateTTL); err != nil {
+			return fmt.Errorf("invalid RemoteUpdateTTL %s of profile %s in %s: %v", p.UpdateTTL, name, fsc.ConfigPath(), err)
+		}
+	}
+	if !fsc.HasProfile(fsc.ActiveProfile()) {
+		return fmt.Errorf("profile %s does not exist", fsc.ActiveProfile())
+	}
+
+	if !migrated {
+		return nil
+	}
+	//keep the previous file and write the migrated configuration. A configuration that cannot be written is migrated on each load.
+	if !isWritable(fsc.ConfigPath()) {
+		fmt.Fprintf(os.Stderr, "warning: %s is read-only and has been migrated to version %d in memory only\n", fsc.ConfigPath(), CurrentVersion)
+		return nil
+	}
+	backupFile, err := backup(fsc.ConfigPath(), yamlFile)
+	if err == nil {
+		err = fsc.Save()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: unable to save the configuration %s migrated to version %d: %v\n", fsc.ConfigPath(), CurrentVersion, err)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "migrated %s to version %d. The previous configuration has been saved to %s\n", fsc.ConfigPath(), CurrentVersion, backupFile)
 	return nil
 }
 
-func (fsc FileSystemConfiguration) Save() error {
-	if err := os.MkdirAll(fsc.Path, os.ModePerm); err != nil {
+// Save writes the configuration. Changes that other processes (e.g. parallel CI jobs) have saved since the configuration has been
+// loaded are merged unless they affect the same profile.
+func (fsc *FileSystemConfiguration) Save() error {
+	if fsc.unreadable {
+		return fmt.Errorf("unable to save the configuration as %s could not be read. Use 'sapper config edit' to fix it", fsc.ConfigPath())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fsc.ConfigPath()), os.ModePerm); err != nil {
 		return err
 	}
 
@@ -70,26 +195,237 @@ func (fsc FileSystemConfiguration) Save() error {
 		return err
 	}
 
+	unlock, err := utils.LockFile(fsc.ConfigPath()+".lock", os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := fsc.mergeConcurrentChanges(); err != nil {
+		return fmt.Errorf("unable to save the configuration %s: %v. Please retry", fsc.ConfigPath(), err)
+	}
+
+	fsc.Version = CurrentVersion
 	yamlData, err := yaml.Marshal(fsc)
 	if err != nil {
 		return err
 	}
-	err = ioutil.WriteFile(fsc.ConfigPath(), yamlData, 0644)
+	if err := utils.WriteFileAtomically(fsc.ConfigPath(), yamlData, 0644); err != nil {
+		return err
+	}
+	saved, err := fsc.snapshot()
 	if err != nil {
 		return err
 	}
+	fsc.loaded = &saved
 	return nil
 }
 
 func (fsc FileSystemConfiguration) DefaultRemotesDir() string {
 	return path.Join(fsc.Path, "remotes")
 }
+
+// ActiveProfile returns the name of the profile in use
+func (fsc FileSystemConfiguration) ActiveProfile() string {
+	if fsc.Override != "" {
+		return fsc.Override
+	}
+	if fsc.Active != "" {
+		return fsc.Active
+	}
+	return DefaultProfile
+}
+
+// ProfileNames returns the default profile followed by the other profiles in alphabetical order
+func (fsc FileSystemConfiguration) ProfileNames() []string {
+	names := []string{}
+	for name := range fsc.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+func (fsc FileSystemConfiguration) HasProfile(name string) bool {
+	_, ok := fsc.Profiles[name]
+	return ok || name == DefaultProfile
+}
+
+// UseProfile activates a profile. The profile remains active until another profile is activated.
+func (fsc *FileSystemConfiguration) UseProfile(name string) error {
+	if !fsc.HasProfile(name) {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	fsc.Active = name
+	if name == DefaultProfile {
+		fsc.Active = ""
+	}
+	fsc.Override = ""
+	return nil
+}
+
+// AddProfile adds a profile that initially contains the default remote
+func (fsc *FileSystemConfiguration) AddProfile(name string) error {
+	if fsc.HasProfile(name) {
+		return fmt.Errorf("profile %s does already exist", name)
+	}
+	if fsc.Profiles == nil {
+		fsc.Profiles = map[string]Profile{}
+	}
+	fsc.Profiles[name] = Profile{Rmts: []ports.Remote{defaultRemote}}
+	return nil
+}
+
+func (fsc *FileSystemConfiguration) RemoveProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the %s profile cannot be removed", DefaultProfile)
+	}
+	if !fsc.HasProfile(name) {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	if name == fsc.ActiveProfile() {
+		return fmt.Errorf("profile %s is active", name)
+	}
+	delete(fsc.Profiles, name)
+	return nil
+}
+
+func (fsc FileSystemConfiguration) profile(name string) Profile {
+	if name == DefaultProfile {
+		return Profile{Rmts: fsc.Rmts, BrickPins: fsc.BrickPins, UpdateTTL: fsc.UpdateTTL, Params: fsc.Params}
+	}
+	return fsc.Profiles[name]
+}
+
+func (fsc *FileSystemConfiguration) setProfile(name string, p Profile) {
+	if name == DefaultProfile {
+		fsc.Rmts, fsc.BrickPins, fsc.UpdateTTL, fsc.Params = p.Rmts, p.BrickPins, p.UpdateTTL, p.Params
+		return
+	}
+	fsc.Profiles[name] = p
+}
+
+// Remotes returns the remotes of the project followed by the remotes of the active profile that are not overridden by the project
 func (fsc FileSystemConfiguration) Remotes() []ports.Remote {
-	return fsc.Rmts
+	rmts := fsc.profile(fsc.ActiveProfile()).Rmts
+	if fsc.Project == nil {
+		return rmts
+	}
+	remotes := append([]ports.Remote{}, fsc.Project.Rmts...)
+	for _, r := range rmts {
+		if !fsc.Project.hasRemote(r.Name) {
+			remotes = append(remotes, r)
+		}
+	}
+	return remotes
 }
 
+// UpdateRemotes updates the remotes of the active profile. Unchanged remotes of the project are not stored.
 func (fsc *FileSystemConfiguration) UpdateRemotes(remotes []ports.Remote) {
-	fsc.Rmts = remotes
+	p := fsc.profile(fsc.ActiveProfile())
+	defer func() { fsc.setProfile(fsc.ActiveProfile(), p) }()
+	if fsc.Project == nil {
+		p.Rmts = remotes
+		return
+	}
+	userRemotes := []ports.Remote{}
+	for _, r := range remotes {
+		if !fsc.Project.containsRemote(r) {
+			userRemotes = append(userRemotes, r)
+		}
+	}
+	//keep the user's remotes that are hidden by the project at their position
+	for i, r := range p.Rmts {
+		if !fsc.Project.hasRemote(r.Name) {
+			continue
+		}
+		if i >= len(userRemotes) {
+			userRemotes = append(userRemotes, r)
+			continue
+		}
+		userRemotes = append(userRemotes[:i+1], userRemotes[i:]...)
+		userRemotes[i] = r
+	}
+	p.Rmts = userRemotes
+}
+
+// ProjectRemotes returns the remotes of the project
+func (fsc FileSystemConfiguration) ProjectRemotes() []ports.Remote {
+	if fsc.Project == nil {
+		return []ports.Remote{}
+	}
+	return fsc.Project.Rmts
+}
+
+// Pins returns the pins of the project followed by the pins of the active profile
+func (fsc FileSystemConfiguration) Pins() []ports.BrickPin {
+	pins := fsc.profile(fsc.ActiveProfile()).BrickPins
+	if fsc.Project == nil {
+		return pins
+	}
+	return append(append([]ports.BrickPin{}, fsc.Project.BrickPins...), pins...)
+}
+
+// UpdatePins updates the pins of the active profile. Unchanged pins of the project are not stored.
+func (fsc *FileSystemConfiguration) UpdatePins(pins []ports.BrickPin) {
+	p := fsc.profile(fsc.ActiveProfile())
+	defer func() { fsc.setProfile(fsc.ActiveProfile(), p) }()
+	if fsc.Project == nil {
+		p.BrickPins = pins
+		return
+	}
+	userPins := []ports.BrickPin{}
+	for _, pin := range pins {
+		if !fsc.Project.containsPin(pin) {
+			userPins = append(userPins, pin)
+		}
+	}
+	p.BrickPins = userPins
+}
+
+// Parameters returns the parameters of the active profile overridden by the parameters of the project
+func (fsc FileSystemConfiguration) Parameters() map[string]string {
+	parameters := map[string]string{}
+	for k, v := range fsc.profile(fsc.ActiveProfile()).Params {
+		parameters[k] = v
+	}
+	if fsc.Project != nil {
+		for k, v := range fsc.Project.Params {
+			parameters[k] = v
+		}
+	}
+	return parameters
+}
+
+// UpdateParameters updates the parameters of the active profile. Unchanged parameters of the project are not stored.
+func (fsc *FileSystemConfiguration) UpdateParameters(parameters map[string]string) {
+	p := fsc.profile(fsc.ActiveProfile())
+	userParams := map[string]string{}
+	for k, v := range parameters {
+		if fsc.Project != nil {
+			if projectValue, ok := fsc.Project.Params[k]; ok && projectValue == v {
+				if userValue, ok := p.Params[k]; ok { //keep the user's value that is hidden by the project
+					userParams[k] = userValue
+				}
+				continue
+			}
+		}
+		userParams[k] = v
+	}
+	p.Params = userParams
+	fsc.setProfile(fsc.ActiveProfile(), p)
+}
+
+func (fsc FileSystemConfiguration) RemoteUpdateTTL() time.Duration {
+	updateTTL := fsc.profile(fsc.ActiveProfile()).UpdateTTL
+	if fsc.Project != nil && fsc.Project.UpdateTTL != "" {
+		updateTTL = fsc.Project.UpdateTTL
+	}
+	ttl, err := time.ParseDuration(updateTTL)
+	if err != nil {
+		return 0
+	}
+	return ttl
 }
 
 var _ ports.Configuration = (*FileSystemConfiguration)(nil)
+var _ ports.ProfileConfiguration = (*FileSystemConfiguration)(nil)
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/seboste/sapper/ports"
 )
@@ -31,13 +32,30 @@ func TestFileSystemConfiguration_Load(t *testing.T) {
     - name: some-remote
       src: some-path
 `, Rmts: []ports.Remote{}},
-			wantConfig: FileSystemConfiguration{Path: tempDir, Rmts: []ports.Remote{{Name: "some-remote", Src: "some-path"}}},
+			wantConfig: FileSystemConfiguration{Path: tempDir, Version: CurrentVersion, Rmts: []ports.Remote{{Name: "some-remote", Src: "some-path"}}},
 			wantErr:    func(err error) bool { return err == nil }},
+		{name: "config with remote update ttl", fields: fields{Path: tempDir, Yaml: `Remotes: []
+RemoteUpdateTTL: 24h
+`, Rmts: []ports.Remote{}},
+			wantConfig: FileSystemConfiguration{Path: tempDir, Version: CurrentVersion, Rmts: []ports.Remote{}, UpdateTTL: "24h"},
+			wantErr:    func(err error) bool { return err == nil }},
+		{name: "config with pins", fields: fields{Path: tempDir, Yaml: `Remotes: []
+Pins:
+    - brick: handler-*
+      remote: internal
+`, Rmts: []ports.Remote{}},
+			wantConfig: FileSystemConfiguration{Path: tempDir, Version: CurrentVersion, Rmts: []ports.Remote{}, BrickPins: []ports.BrickPin{{Brick: "handler-*", Remote: "internal"}}},
+			wantErr:    func(err error) bool { return err == nil }},
+		{name: "invalid remote update ttl", fields: fields{Path: tempDir, Yaml: `Remotes: []
+RemoteUpdateTTL: one day
+`, Rmts: []ports.Remote{}},
+			wantConfig: FileSystemConfiguration{Path: tempDir, Version: CurrentVersion, Rmts: []ports.Remote{}, UpdateTTL: "one day"},
+			wantErr:    func(err error) bool { return err != nil && !os.IsNotExist(err) }},
 		{name: "invalid yaml syntax", fields: fields{Path: tempDir, Yaml: `Remotes:
     - name: some-remote
          src: some-path
 `, Rmts: []ports.Remote{}},
-			wantConfig: FileSystemConfiguration{Path: tempDir, Rmts: []ports.Remote{}},
+			wantConfig: FileSystemConfiguration{Path: tempDir, Rmts: []ports.Remote{}, unreadable: true},
 			wantErr:    func(err error) bool { return err != nil && !os.IsNotExist(err) }},
 		{name: "file does not exist", fields: fields{Path: tempDir, Yaml: "", Rmts: []ports.Remote{}},
 			wantConfig: FileSystemConfiguration{Path: tempDir, Rmts: []ports.Remote{}},
@@ -61,7 +79,7 @@ func TestFileSystemConfiguration_Load(t *testing.T) {
 				t.Errorf("FileSystemConfiguration.Load() error = %v, wantErr %v", err, tt.wantErr(err))
 			}
 
-			if !reflect.DeepEqual(fsc, tt.wantConfig) {
+			if !reflect.DeepEqual(withoutSnapshot(fsc), tt.wantConfig) {
 				t.Errorf("FileSystemConfiguration.Load() config = %v, want %v", fsc, tt.wantConfig)
 			}
 		})
@@ -137,9 +155,11 @@ func TestFileSystemConfiguration_Save(t *testing.T) {
 		wantErr  bool
 		wantYaml string
 	}{
-		{name: "config without remotes", fields: fields{Path: filepath.Join(tempDir, "test1")}, wantErr: false, wantYaml: `Remotes: []
+		{name: "config without remotes", fields: fields{Path: filepath.Join(tempDir, "test1")}, wantErr: false, wantYaml: `Version: 1
+Remotes: []
 `},
-		{name: "config with remotes", fields: fields{Path: filepath.Join(tempDir, "test2"), Rmts: []ports.Remote{{Name: "some-remote", Src: "some-path"}}}, wantErr: false, wantYaml: `Remotes:
+		{name: "config with remotes", fields: fields{Path: filepath.Join(tempDir, "test2"), Rmts: []ports.Remote{{Name: "some-remote", Src: "some-path"}}}, wantErr: false, wantYaml: `Version: 1
+Remotes:
     - name: some-remote
       kind: 0
       src: some-path
@@ -166,38 +186,114 @@ func TestFileSystemConfiguration_Save(t *testing.T) {
 func TestMakeFilesystemConfiguration(t *testing.T) {
 
 	testConfig := FileSystemConfiguration{Rmts: []ports.Remote{{Name: "some-remote", Src: "some-path"}}}
+	customConfigFile := filepath.Join(os.TempDir(), "MakeFscTestCustomConfig.yaml")
+	defer os.Remove(customConfigFile)
 
 	tests := []struct {
 		name          string
+		configFile    string
 		initialConfig *FileSystemConfiguration
 		want          FileSystemConfiguration
 		wantErr       bool
 	}{
 		{name: "config exists", initialConfig: &testConfig, want: testConfig, wantErr: false},
-		{name: "config does not exist", initialConfig: nil, want: defaultConfiguration, wantErr: false},
+		{name: "config does not exist", initialConfig: nil, want: defaultConfiguration(""), wantErr: false},
+		{name: "custom config file", configFile: customConfigFile, initialConfig: &testConfig, want: testConfig, wantErr: false},
+		{name: "custom config file does not exist", configFile: filepath.Join(os.TempDir(), "MakeFscTestMissing.yaml"), initialConfig: nil, want: FileSystemConfiguration{}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			savePath := defaultConfiguration.Path
-			defer func() { defaultConfiguration.Path = savePath }()
-
-			defaultConfiguration.Path, _ = ioutil.TempDir("", "MakeFscTest*")
-			os.RemoveAll(defaultConfiguration.Path)
+			homeDir, _ := ioutil.TempDir("", "MakeFscTest*")
+			os.RemoveAll(homeDir)
+			defer os.RemoveAll(homeDir)
+			t.Setenv(HomeEnv, homeDir)
 
 			if tt.initialConfig != nil {
-				tt.initialConfig.Path = defaultConfiguration.Path
-				tt.initialConfig.Save()
+				initialConfig := *tt.initialConfig
+				initialConfig.Path = homeDir
+				initialConfig.File = tt.configFile
+				initialConfig.Save()
 			}
-			tt.want.Path = defaultConfiguration.Path
+			tt.want.Path = homeDir
+			tt.want.File = tt.configFile
+			tt.want.Version = CurrentVersion
 
-			got, err := MakeFilesystemConfiguration()
+			got, err := MakeFilesystemConfiguration(tt.configFile, "", "")
 			if (err != nil) != tt.wantErr {
 				t.Errorf("MakeFilesystemConfiguration() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if !tt.wantErr && !reflect.DeepEqual(withoutSnapshot(got), tt.want) {
 				t.Errorf("MakeFilesystemConfiguration() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestMakeFilesystemConfiguration_Project(t *testing.T) {
+	homeDir, _ := ioutil.TempDir("", "MakeFscProjectTest*")
+	defer os.RemoveAll(homeDir)
+	t.Setenv(HomeEnv, homeDir)
+
+	projectDir := filepath.Join(homeDir, "monorepo")
+	serviceDir := filepath.Join(projectDir, "services", "my-service")
+	os.MkdirAll(serviceDir, os.ModePerm)
+	ioutil.WriteFile(filepath.Join(projectDir, ProjectConfigurationFile), []byte(`Remotes:
+    - name: monorepo-bricks
+      kind: 0
+      src: bricks
+`), 0644)
+
+	got, err := MakeFilesystemConfiguration("", "", serviceDir)
+	if err != nil {
+		t.Fatalf("MakeFilesystemConfiguration() error = %v", err)
+	}
+	want := []ports.Remote{{Name: "monorepo-bricks", Kind: ports.FilesystemRemote, Src: filepath.Join(projectDir, "bricks")}, defaultRemote}
+	if !reflect.DeepEqual(got.Remotes(), want) {
+		t.Errorf("MakeFilesystemConfiguration() remotes = %v, want %v", got.Remotes(), want)
+	}
+}
+
+func TestHomeDir(t *testing.T) {
+	userHomeDir, _ := os.UserHomeDir()
+	tests := []struct {
+		name       string
+		sapperHome string
+		want       string
+	}{
+		{name: "default", sapperHome: "", want: filepath.Join(userHomeDir, ".sapper")},
+		{name: "SAPPER_HOME", sapperHome: "/opt/sapper", want: "/opt/sapper"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(HomeEnv, tt.sapperHome)
+			got, err := HomeDir()
+			if err != nil {
+				t.Errorf("HomeDir() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("HomeDir() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSystemConfiguration_RemoteUpdateTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		updateTTL string
+		want      time.Duration
+	}{
+		{name: "not configured", updateTTL: "", want: 0},
+		{name: "hours", updateTTL: "24h", want: 24 * time.Hour},
+		{name: "invalid", updateTTL: "one day", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsc := FileSystemConfiguration{UpdateTTL: tt.updateTTL}
+			if got := fsc.RemoteUpdateTTL(); got != tt.want {
+				t.Errorf("FileSystemConfiguration.RemoteUpdateTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// snapshot is the state of a configuration that is written to the config file in a form that can be compared
+type snapshot struct {
+	profiles map[string]string //profile name->profile as yaml. Includes the default profile.
+	active   string
+}
+
+func (fsc FileSystemConfiguration) snapshot() (snapshot, error) {
+	s := snapshot{profiles: map[string]string{}, active: fsc.Active}
+	for _, name := range fsc.ProfileNames() {
+		data, err := yaml.Marshal(fsc.profile(name))
+		if err != nil {
+			return snapshot{}, err
+		}
+		s.profiles[name] = string(data)
+	}
+	return s, nil
+}
+
+// restore replaces the profiles and the active profile by the ones of the snapshot
+func (fsc *FileSystemConfiguration) restore(s snapshot) error {
+	fsc.Profiles = nil
+	fsc.Active = s.active
+	for name, data := range s.profiles {
+		p := Profile{}
+		if err := yaml.Unmarshal([]byte(data), &p); err != nil {
+			return err
+		}
+		if name != DefaultProfile && fsc.Profiles == nil {
+			fsc.Profiles = map[string]Profile{}
+		}
+		fsc.setProfile(name, p)
+	}
+	return nil
+}
+
+// merge3 returns the value that contains the changes from base to ours as well as from base to theirs. It fails if both have
+// changed the value differently.
+func merge3(base string, ours string, theirs string) (string, bool) {
+	switch {
+	case ours == base:
+		return theirs, true
+	case theirs == base || theirs == ours:
+		return ours, true
+	}
+	return "", false
+}
+
+// mergeSnapshots applies the changes from base to ours to theirs. Profiles are merged as a whole, i.e. a profile that has been
+// changed in ours and differently in theirs is a conflict.
+func mergeSnapshots(base snapshot, ours snapshot, theirs snapshot) (snapshot, error) {
+	names := map[string]bool{}
+	for _, s := range []snapshot{base, ours, theirs} {
+		for name := range s.profiles {
+			names[name] = true
+		}
+	}
+
+	merged := snapshot{profiles: map[string]string{}}
+	for name := range names {
+		p, ok := merge3(base.profiles[name], ours.profiles[name], theirs.profiles[name])
+		if !ok {
+			return snapshot{}, fmt.Errorf("profile %s has been changed by another process", name)
+		}
+		if p != "" {
+			merged.profiles[name] = p
+		}
+	}
+
+	active, ok := merge3(base.active, ours.active, theirs.active)
+	if !ok {
+		return snapshot{}, errors.New("the active profile has been changed by another process")
+	}
+	if _, ok := merged.profiles[active]; active != "" && !ok {
+		return snapshot{}, fmt.Errorf("the active profile %s has been removed by another process", active)
+	}
+	merged.active = active
+	return merged, nil
+}
+
+// mergeConcurrentChanges applies the changes that other processes have saved since the configuration has been loaded
+func (fsc *FileSystemConfiguration) mergeConcurrentChanges() error {
+	if fsc.loaded == nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(fsc.ConfigPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	current := FileSystemConfiguration{}
+	if _, err := decode(data, &current); err != nil {
+		return err
+	}
+
+	theirs, err := current.snapshot()
+	if err != nil {
+		return err
+	}
+	ours, err := fsc.snapshot()
+	if err != nil {
+		return err
+	}
+	merged, err := mergeSnapshots(*fsc.loaded, ours, theirs)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(merged, ours) {
+		return nil
+	}
+	return fsc.restore(merged)
+}
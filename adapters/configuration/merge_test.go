@@ -0,0 +1,92 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+// withoutSnapshot drops the content of the config file that a configuration keeps to merge concurrent changes
+func withoutSnapshot(fsc FileSystemConfiguration) FileSystemConfiguration {
+	fsc.loaded = nil
+	return fsc
+}
+
+func Test_mergeSnapshots(t *testing.T) {
+	base := snapshot{profiles: map[string]string{DefaultProfile: "a", "p": "b"}}
+	tests := []struct {
+		name    string
+		ours    snapshot
+		theirs  snapshot
+		want    snapshot
+		wantErr bool
+	}{
+		{name: "unchanged", ours: base, theirs: base, want: base},
+		{name: "changed by us", ours: snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}}, theirs: base, want: snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}}},
+		{name: "changed by them", ours: base, theirs: snapshot{profiles: map[string]string{DefaultProfile: "a", "p": "b2"}}, want: snapshot{profiles: map[string]string{DefaultProfile: "a", "p": "b2"}}},
+		{name: "different profiles changed",
+			ours:   snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}, active: "p"},
+			theirs: snapshot{profiles: map[string]string{DefaultProfile: "a", "p": "b2", "q": "c"}},
+			want:   snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b2", "q": "c"}, active: "p"},
+		},
+		{name: "profile removed by them", ours: base, theirs: snapshot{profiles: map[string]string{DefaultProfile: "a"}}, want: snapshot{profiles: map[string]string{DefaultProfile: "a"}}},
+		{name: "same change", ours: snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}}, theirs: snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}}, want: snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}}},
+		{name: "same profile changed", ours: snapshot{profiles: map[string]string{DefaultProfile: "a2", "p": "b"}}, theirs: snapshot{profiles: map[string]string{DefaultProfile: "a3", "p": "b"}}, wantErr: true},
+		{name: "active profile removed by them", ours: snapshot{profiles: base.profiles, active: "p"}, theirs: snapshot{profiles: map[string]string{DefaultProfile: "a"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeSnapshots(base, tt.ours, tt.theirs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeSnapshots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeSnapshots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSystemConfiguration_SaveMergesConcurrentChanges(t *testing.T) {
+	tempDir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte("Version: 1\nRemotes: []\nProfiles:\n    internal:\n        Remotes: []\n"), 0644)
+
+	first, second := FileSystemConfiguration{Path: tempDir}, FileSystemConfiguration{Path: tempDir}
+	if err := first.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	first.UpdateRemotes([]ports.Remote{{Name: "first", Src: "/first"}})
+	if err := first.Save(); err != nil {
+		t.Fatalf("FileSystemConfiguration.Save() error = %v", err)
+	}
+	second.UseProfile("internal")
+	second.UpdateRemotes([]ports.Remote{{Name: "second", Src: "/second"}})
+	if err := second.Save(); err != nil {
+		t.Fatalf("FileSystemConfiguration.Save() of concurrent change error = %v", err)
+	}
+
+	got := FileSystemConfiguration{Path: tempDir}
+	if err := got.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []ports.Remote{{Name: "first", Src: "/first"}}; !reflect.DeepEqual(got.Rmts, want) {
+		t.Errorf("FileSystemConfiguration.Save() default remotes = %v, want %v", got.Rmts, want)
+	}
+	if want := []ports.Remote{{Name: "second", Src: "/second"}}; !reflect.DeepEqual(got.Remotes(), want) || got.ActiveProfile() != "internal" {
+		t.Errorf("FileSystemConfiguration.Save() remotes of %s = %v, want %v", got.ActiveProfile(), got.Remotes(), want)
+	}
+
+	//a change of the same profile must not be lost
+	first.UseProfile("internal")
+	first.UpdateRemotes([]ports.Remote{{Name: "other", Src: "/other"}})
+	if err := first.Save(); err == nil {
+		t.Errorf("FileSystemConfiguration.Save() of conflicting change succeeded")
+	}
+}
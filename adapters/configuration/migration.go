@@ -0,0 +1,100 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the configuration format that is written by this version of sapper. Configuration files
+// without a Version have been written before the format was versioned. Their layout is the one of version 1.
+const CurrentVersion = 1
+
+// migration transforms the raw configuration of one version into the next version
+type migration func(doc map[string]interface{}) error
+
+// migrations[i] migrates a configuration of version i+1 to version i+2. The format has not changed since version 1 yet.
+var migrations = []migration{}
+
+func configVersion(doc map[string]interface{}) (int, error) {
+	v, ok := doc["Version"]
+	if !ok {
+		return 1, nil
+	}
+	version, ok := v.(int)
+	if !ok || version < 1 {
+		return 0, fmt.Errorf("invalid Version %v", v)
+	}
+	return version, nil
+}
+
+// migrate brings a configuration to the current version. It returns whether the configuration has been migrated.
+func migrate(data []byte) ([]byte, bool, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	version, err := configVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > CurrentVersion {
+		return nil, false, fmt.Errorf("configuration version %d is not supported by this version of sapper (supported up to version %d). Please upgrade sapper", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, false, nil
+	}
+
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version-1](doc); err != nil {
+			return nil, false, fmt.Errorf("unable to migrate configuration from version %d to %d: %v", version, version+1, err)
+		}
+	}
+	doc["Version"] = CurrentVersion
+
+	migrated, err := yaml.Marshal(doc)
+	return migrated, true, err
+}
+
+// backupPath returns the path that a configuration file of the given version is backed up to before it is migrated. The n-th
+// backup of the same version gets a counter so that earlier backups are kept.
+func backupPath(configPath string, version int, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%s.v%d.bak", configPath, version)
+	}
+	return fmt.Sprintf("%s.v%d.%d.bak", configPath, version, n)
+}
+
+// backup writes data to a new backup file. Existing backups are never overwritten.
+func backup(configPath string, data []byte) (string, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+	version, err := configVersion(doc)
+	if err != nil {
+		return "", err
+	}
+	for n := 0; ; n++ {
+		path := backupPath(configPath, version, n)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
+
+// isWritable tells whether the user allows a file to be written. A read-only file (e.g. a config provisioned for CI) is not rewritten.
+func isWritable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0200 != 0
+}
@@ -0,0 +1,94 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func TestFileSystemConfiguration_LoadVersions(t *testing.T) {
+	sapperBricks := ports.Remote{Name: "sapper-bricks", Kind: ports.GitRemote, Src: "https://github.com/seboste/sapper-bricks.git"}
+
+	tests := []struct {
+		fixture string
+		want    FileSystemConfiguration
+		wantErr bool
+	}{
+		{fixture: "unversioned.yaml", want: FileSystemConfiguration{Version: 1, Rmts: []ports.Remote{sapperBricks}}},
+		{fixture: "v1.yaml", want: FileSystemConfiguration{Version: 1, Rmts: []ports.Remote{sapperBricks}, Params: map[string]string{"CONTAINER_REGISTRY": "registry.example.com"}}},
+		{fixture: "v99.yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			tempDir := t.TempDir()
+			original, err := ioutil.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ioutil.WriteFile(filepath.Join(tempDir, "config.yaml"), original, 0644)
+
+			fsc := FileSystemConfiguration{Path: tempDir}
+			if err := fsc.Load(); (err != nil) != tt.wantErr {
+				t.Fatalf("FileSystemConfiguration.Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			tt.want.Path = tempDir
+			if !reflect.DeepEqual(withoutSnapshot(fsc), tt.want) {
+				t.Errorf("FileSystemConfiguration.Load() config = %v, want %v", fsc, tt.want)
+			}
+			if rewritten, _ := ioutil.ReadFile(filepath.Join(tempDir, "config.yaml")); string(rewritten) != string(original) {
+				t.Errorf("FileSystemConfiguration.Load() rewrote the config to %v", string(rewritten))
+			}
+		})
+	}
+}
+
+func Test_backup(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	ioutil.WriteFile(filepath.Join(tempDir, "config.yaml.v1.bak"), []byte("earlier backup"), 0644)
+
+	data := []byte("Version: 1\nRemotes: []\n")
+	path, err := backup(configPath, data)
+	if err != nil {
+		t.Fatalf("backup() error = %v", err)
+	}
+	if want := filepath.Join(tempDir, "config.yaml.v1.1.bak"); path != want {
+		t.Errorf("backup() = %v, want %v", path, want)
+	}
+	if content, _ := ioutil.ReadFile(path); string(content) != string(data) {
+		t.Errorf("backup() content = %v, want %v", string(content), string(data))
+	}
+	if content, _ := ioutil.ReadFile(filepath.Join(tempDir, "config.yaml.v1.bak")); string(content) != "earlier backup" {
+		t.Errorf("backup() overwrote the existing backup with %v", string(content))
+	}
+}
+
+func Test_isWritable(t *testing.T) {
+	tempDir := t.TempDir()
+	writable, readOnly := filepath.Join(tempDir, "writable.yaml"), filepath.Join(tempDir, "read-only.yaml")
+	ioutil.WriteFile(writable, []byte{}, 0644)
+	ioutil.WriteFile(readOnly, []byte{}, 0444)
+
+	if !isWritable(writable) {
+		t.Errorf("isWritable(%s) = false, want true", writable)
+	}
+	if isWritable(readOnly) {
+		t.Errorf("isWritable(%s) = true, want false", readOnly)
+	}
+	if isWritable(filepath.Join(tempDir, "missing.yaml")) {
+		t.Errorf("isWritable() of a missing file = true, want false")
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	if len(migrations) != CurrentVersion-1 {
+		t.Errorf("there are %d migrations for configuration version %d", len(migrations), CurrentVersion)
+	}
+}
@@ -0,0 +1,137 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func TestFileSystemConfiguration_Profiles(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "fscProfileTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	ioutil.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(`Remotes:
+    - name: sapper-bricks
+      kind: 1
+      src: https://github.com/seboste/sapper-bricks.git
+Profiles:
+    internal:
+        Remotes:
+            - name: company-bricks
+              kind: 1
+              src: https://git.example.com/bricks.git
+        RemoteUpdateTTL: 1h
+ActiveProfile: internal
+`), 0644)
+
+	tests := []struct {
+		name        string
+		override    string
+		wantErr     bool
+		wantProfile string
+		wantRemotes []ports.Remote
+	}{
+		{name: "active profile", override: "", wantProfile: "internal", wantRemotes: []ports.Remote{{Name: "company-bricks", Kind: ports.GitRemote, Src: "https://git.example.com/bricks.git"}}},
+		{name: "default profile selected", override: DefaultProfile, wantProfile: DefaultProfile, wantRemotes: []ports.Remote{defaultRemote}},
+		{name: "unknown profile selected", override: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsc := FileSystemConfiguration{Path: tempDir, Override: tt.override}
+			if err := fsc.Load(); (err != nil) != tt.wantErr {
+				t.Errorf("FileSystemConfiguration.Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := fsc.ActiveProfile(); got != tt.wantProfile {
+				t.Errorf("FileSystemConfiguration.ActiveProfile() = %v, want %v", got, tt.wantProfile)
+			}
+			if got := fsc.Remotes(); !reflect.DeepEqual(got, tt.wantRemotes) {
+				t.Errorf("FileSystemConfiguration.Remotes() = %v, want %v", got, tt.wantRemotes)
+			}
+		})
+	}
+}
+
+func TestFileSystemConfiguration_ManageProfiles(t *testing.T) {
+	fsc := FileSystemConfiguration{Rmts: []ports.Remote{{Name: "local", Src: "/bricks"}}}
+
+	if err := fsc.AddProfile("oss"); err != nil {
+		t.Fatalf("FileSystemConfiguration.AddProfile() error = %v", err)
+	}
+	if err := fsc.AddProfile("oss"); err == nil {
+		t.Errorf("FileSystemConfiguration.AddProfile() of existing profile succeeded")
+	}
+	if err := fsc.AddProfile(DefaultProfile); err == nil {
+		t.Errorf("FileSystemConfiguration.AddProfile() of default profile succeeded")
+	}
+	if got, want := fsc.ProfileNames(), []string{DefaultProfile, "oss"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemConfiguration.ProfileNames() = %v, want %v", got, want)
+	}
+
+	if err := fsc.UseProfile("oss"); err != nil {
+		t.Fatalf("FileSystemConfiguration.UseProfile() error = %v", err)
+	}
+	if got, want := fsc.Remotes(), []ports.Remote{defaultRemote}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemConfiguration.Remotes() = %v, want %v", got, want)
+	}
+	fsc.UpdateRemotes(append(fsc.Remotes(), ports.Remote{Name: "more", Src: "/more"}))
+	fsc.UpdatePins([]ports.BrickPin{{Brick: "x", Remote: "more"}})
+	if got, want := fsc.Profiles["oss"].Rmts, []ports.Remote{defaultRemote, {Name: "more", Src: "/more"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemConfiguration.UpdateRemotes() profile remotes = %v, want %v", got, want)
+	}
+	if got, want := fsc.Rmts, []ports.Remote{{Name: "local", Src: "/bricks"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemConfiguration.UpdateRemotes() default remotes = %v, want %v", got, want)
+	}
+	if fsc.BrickPins != nil {
+		t.Errorf("FileSystemConfiguration.UpdatePins() changed the default profile's pins")
+	}
+
+	if err := fsc.RemoveProfile("oss"); err == nil {
+		t.Errorf("FileSystemConfiguration.RemoveProfile() of active profile succeeded")
+	}
+	if err := fsc.UseProfile(DefaultProfile); err != nil || fsc.Active != "" {
+		t.Errorf("FileSystemConfiguration.UseProfile() error = %v, active = %v", err, fsc.Active)
+	}
+	if err := fsc.RemoveProfile("oss"); err != nil {
+		t.Errorf("FileSystemConfiguration.RemoveProfile() error = %v", err)
+	}
+	if err := fsc.UseProfile("oss"); err == nil {
+		t.Errorf("FileSystemConfiguration.UseProfile() of removed profile succeeded")
+	}
+}
+
+func TestFileSystemConfiguration_RecoverFromInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		wantSaveErr bool
+	}{
+		{name: "missing active profile", config: "Remotes: []\nActiveProfile: missing\n", wantSaveErr: false},
+		{name: "unreadable config", config: "Remotes: [\n", wantSaveErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			file := filepath.Join(tempDir, "config.yaml")
+			ioutil.WriteFile(file, []byte(tt.config), 0644)
+
+			fsc := FileSystemConfiguration{Path: tempDir}
+			if err := fsc.Load(); err == nil {
+				t.Fatalf("FileSystemConfiguration.Load() error = nil, want an error")
+			}
+			fsc.UseProfile(DefaultProfile)
+			if err := fsc.Save(); (err != nil) != tt.wantSaveErr {
+				t.Errorf("FileSystemConfiguration.Save() error = %v, wantErr %v", err, tt.wantSaveErr)
+			}
+			if content, _ := ioutil.ReadFile(file); tt.wantSaveErr && string(content) != tt.config {
+				t.Errorf("FileSystemConfiguration.Save() overwrote the unreadable config with %q", string(content))
+			}
+		})
+	}
+}
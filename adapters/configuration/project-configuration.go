@@ -0,0 +1,99 @@
+package configuration
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/seboste/sapper/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigurationFile is the name of the project configuration that is searched for in a service folder and its parents
+const ProjectConfigurationFile = ".sapper.yaml"
+
+// ProjectConfiguration adds to or overrides the user's configuration for all services within a folder (e.g. a monorepo)
+type ProjectConfiguration struct {
+	Path      string            `yaml:"-"`
+	Rmts      []ports.Remote    `yaml:"Remotes,omitempty"` //take precedence over the user's remotes. Relative paths are relative to the project configuration.
+	BrickPins []ports.BrickPin  `yaml:"Pins,omitempty"`    //take precedence over the user's pins
+	UpdateTTL string            `yaml:"RemoteUpdateTTL,omitempty"`
+	Params    map[string]string `yaml:"Parameters,omitempty"` //override the user's parameters
+}
+
+// FindProjectConfiguration searches for the project configuration in dir and its parents
+func FindProjectConfiguration(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigurationFile)
+		if fileInfo, err := os.Stat(path); err == nil && !fileInfo.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func LoadProjectConfiguration(path string) (ProjectConfiguration, error) {
+	pc := ProjectConfiguration{Path: path}
+	yamlFile, err := ioutil.ReadFile(path)
+	if err != nil {
+		return pc, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlFile))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&pc); err != nil && err != io.EOF {
+		return pc, fmt.Errorf("invalid project configuration %s: %v", path, err)
+	}
+	if pc.UpdateTTL != "" {
+		if _, err := time.ParseDuration(pc.UpdateTTL); err != nil {
+			return pc, fmt.Errorf("invalid RemoteUpdateTTL %s in %s: %v", pc.UpdateTTL, path, err)
+		}
+	}
+	for i, r := range pc.Rmts {
+		if r.Kind == ports.FilesystemRemote && !filepath.IsAbs(r.Src) {
+			pc.Rmts[i].Src = filepath.Join(filepath.Dir(path), r.Src)
+		}
+	}
+	return pc, nil
+}
+
+func (pc ProjectConfiguration) hasRemote(name string) bool {
+	for _, r := range pc.Rmts {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (pc ProjectConfiguration) containsRemote(remote ports.Remote) bool {
+	for _, r := range pc.Rmts {
+		if r == remote {
+			return true
+		}
+	}
+	return false
+}
+
+func (pc ProjectConfiguration) containsPin(pin ports.BrickPin) bool {
+	for _, p := range pc.BrickPins {
+		if p == pin {
+			return true
+		}
+	}
+	return false
+}
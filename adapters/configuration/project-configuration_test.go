@@ -0,0 +1,113 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func TestFindProjectConfiguration(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "findProjectTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	os.MkdirAll(filepath.Join(tempDir, "a", "b", "c"), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(tempDir, "a", ProjectConfigurationFile), []byte("Remotes: []\n"), 0644)
+	os.MkdirAll(filepath.Join(tempDir, "d"), os.ModePerm)
+
+	tests := []struct {
+		name   string
+		dir    string
+		want   string
+		wantOk bool
+	}{
+		{name: "in folder", dir: filepath.Join(tempDir, "a"), want: filepath.Join(tempDir, "a", ProjectConfigurationFile), wantOk: true},
+		{name: "in parent", dir: filepath.Join(tempDir, "a", "b", "c"), want: filepath.Join(tempDir, "a", ProjectConfigurationFile), wantOk: true},
+		{name: "folder does not exist yet", dir: filepath.Join(tempDir, "a", "new-service"), want: filepath.Join(tempDir, "a", ProjectConfigurationFile), wantOk: true},
+		{name: "none", dir: filepath.Join(tempDir, "d"), want: "", wantOk: false},
+		{name: "no folder", dir: "", want: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := FindProjectConfiguration(tt.dir)
+			if gotOk != tt.wantOk {
+				t.Errorf("FindProjectConfiguration() ok = %v, want %v", gotOk, tt.wantOk)
+			}
+			if tt.wantOk && got != tt.want {
+				t.Errorf("FindProjectConfiguration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSystemConfiguration_Project(t *testing.T) {
+	project := ProjectConfiguration{
+		Rmts:      []ports.Remote{{Name: "shared", Src: "/project/shared"}, {Name: "project", Src: "/project/bricks"}},
+		BrickPins: []ports.BrickPin{{Brick: "a", Remote: "project"}},
+		UpdateTTL: "1h",
+	}
+	fsc := FileSystemConfiguration{
+		Project:   &project,
+		Rmts:      []ports.Remote{{Name: "user", Src: "/user/bricks"}, {Name: "shared", Src: "/user/shared"}},
+		BrickPins: []ports.BrickPin{{Brick: "b", Remote: "user"}},
+		UpdateTTL: "24h",
+	}
+
+	wantRemotes := []ports.Remote{{Name: "shared", Src: "/project/shared"}, {Name: "project", Src: "/project/bricks"}, {Name: "user", Src: "/user/bricks"}}
+	if got := fsc.Remotes(); !reflect.DeepEqual(got, wantRemotes) {
+		t.Errorf("FileSystemConfiguration.Remotes() = %v, want %v", got, wantRemotes)
+	}
+	wantPins := []ports.BrickPin{{Brick: "a", Remote: "project"}, {Brick: "b", Remote: "user"}}
+	if got := fsc.Pins(); !reflect.DeepEqual(got, wantPins) {
+		t.Errorf("FileSystemConfiguration.Pins() = %v, want %v", got, wantPins)
+	}
+	if got := fsc.RemoteUpdateTTL().String(); got != "1h0m0s" {
+		t.Errorf("FileSystemConfiguration.RemoteUpdateTTL() = %v, want 1h0m0s", got)
+	}
+
+	//adding a remote must not copy the project's remotes into the user's configuration
+	fsc.UpdateRemotes(append(fsc.Remotes(), ports.Remote{Name: "new", Src: "/new"}))
+	wantUserRemotes := []ports.Remote{{Name: "user", Src: "/user/bricks"}, {Name: "shared", Src: "/user/shared"}, {Name: "new", Src: "/new"}}
+	if !reflect.DeepEqual(fsc.Rmts, wantUserRemotes) {
+		t.Errorf("FileSystemConfiguration.UpdateRemotes() user remotes = %v, want %v", fsc.Rmts, wantUserRemotes)
+	}
+
+	//removing a remote must keep the user's remotes that are hidden by the project at their position
+	remotes := fsc.Remotes()
+	fsc.UpdateRemotes(append(remotes[:2], remotes[3:]...)) //removes "user"
+	wantUserRemotes = []ports.Remote{{Name: "new", Src: "/new"}, {Name: "shared", Src: "/user/shared"}}
+	if !reflect.DeepEqual(fsc.Rmts, wantUserRemotes) {
+		t.Errorf("FileSystemConfiguration.UpdateRemotes() user remotes = %v, want %v", fsc.Rmts, wantUserRemotes)
+	}
+	if got := fsc.ProjectRemotes(); !reflect.DeepEqual(got, project.Rmts) {
+		t.Errorf("FileSystemConfiguration.ProjectRemotes() = %v, want %v", got, project.Rmts)
+	}
+
+	fsc.UpdatePins(append(fsc.Pins(), ports.BrickPin{Brick: "c", Remote: "new"}))
+	wantUserPins := []ports.BrickPin{{Brick: "b", Remote: "user"}, {Brick: "c", Remote: "new"}}
+	if !reflect.DeepEqual(fsc.BrickPins, wantUserPins) {
+		t.Errorf("FileSystemConfiguration.UpdatePins() user pins = %v, want %v", fsc.BrickPins, wantUserPins)
+	}
+}
+
+func TestFileSystemConfiguration_ProjectParameters(t *testing.T) {
+	fsc := FileSystemConfiguration{
+		Project: &ProjectConfiguration{Params: map[string]string{"REGISTRY": "project.example.com"}},
+		Params:  map[string]string{"REGISTRY": "user.example.com", "EMAIL": "me@example.com"},
+	}
+	want := map[string]string{"REGISTRY": "project.example.com", "EMAIL": "me@example.com"}
+	if got := fsc.Parameters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemConfiguration.Parameters() = %v, want %v", got, want)
+	}
+
+	parameters := fsc.Parameters()
+	parameters["PORT"] = "8080"
+	fsc.UpdateParameters(parameters)
+	wantUserParams := map[string]string{"REGISTRY": "user.example.com", "EMAIL": "me@example.com", "PORT": "8080"}
+	if !reflect.DeepEqual(fsc.Params, wantUserParams) {
+		t.Errorf("FileSystemConfiguration.UpdateParameters() user parameters = %v, want %v", fsc.Params, wantUserParams)
+	}
+}
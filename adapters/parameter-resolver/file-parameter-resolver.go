@@ -0,0 +1,153 @@
+package parameterResolver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// FileParameterResolver takes parameters from a YAML, JSON, or dotenv file
+type FileParameterResolver struct {
+	path       string
+	parameters map[string]string
+}
+
+// MakeFileParameterResolver reads the parameters from a file. The format is determined by the extension: .yaml/.yml, .json, or dotenv otherwise.
+func MakeFileParameterResolver(path string) (FileParameterResolver, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return FileParameterResolver{}, err
+	}
+
+	var parameters map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		parameters, err = parseYamlParameters(data)
+	case ".json":
+		parameters, err = parseJsonParameters(data)
+	default:
+		parameters, err = parseDotenvParameters(data)
+	}
+	if err != nil {
+		return FileParameterResolver{}, fmt.Errorf("invalid parameters file %s: %v", path, err)
+	}
+	return FileParameterResolver{path: path, parameters: parameters}, nil
+}
+
+// scalarParameters converts the values of a decoded JSON object to strings. Numbers are kept as written. Nested values are rejected.
+func scalarParameters(values map[string]interface{}) (map[string]string, error) {
+	parameters := map[string]string{}
+	for k, v := range values {
+		switch v := v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("value of parameter %s must be a string, number, or boolean", k)
+		case nil:
+			parameters[k] = ""
+		case json.Number:
+			parameters[k] = v.String()
+		default:
+			parameters[k] = fmt.Sprint(v)
+		}
+	}
+	return parameters, nil
+}
+
+// parseYamlParameters takes the values as written, e.g. 1.10 remains 1.10. Nested values are rejected.
+func parseYamlParameters(data []byte) (map[string]string, error) {
+	values := map[string]yaml.Node{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	parameters := map[string]string{}
+	for k, v := range values {
+		switch {
+		case v.Kind != yaml.ScalarNode:
+			return nil, fmt.Errorf("value of parameter %s must be a string, number, or boolean", k)
+		case v.Tag == "!!null":
+			parameters[k] = ""
+		default:
+			parameters[k] = v.Value
+		}
+	}
+	return parameters, nil
+}
+
+func parseJsonParameters(data []byte) (map[string]string, error) {
+	values := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return scalarParameters(values)
+}
+
+// parseDotenvParameters parses lines of the form [export ]NAME=value. Values may be quoted. Empty lines and lines starting with # are ignored.
+func parseDotenvParameters(data []byte) (map[string]string, error) {
+	parameters := map[string]string{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d must be of the form 'PARAMETER_NAME=value'", i+1)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" {
+			return nil, fmt.Errorf("line %d: parameter name is missing", i+1)
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value %s", i+1, value)
+			}
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		parameters[name] = value
+	}
+	return parameters, nil
+}
+
+func (fpr FileParameterResolver) Resolve(name string, defaultValue string) string {
+	return fpr.parameters[name]
+}
+
+// Validate returns an error if the file contains parameters that are not declared
+func (fpr FileParameterResolver) Validate(declared []ports.BrickParameters) error {
+	known := map[string]bool{}
+	for _, p := range declared {
+		known[p.Name] = true
+	}
+	unknown := []string{}
+	for name := range fpr.parameters {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	names := []string{}
+	for name := range known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown parameters %s in %s. Known parameters are %s", strings.Join(unknown, ", "), fpr.path, strings.Join(names, ", "))
+}
+
+var _ ports.ParameterResolver = FileParameterResolver{}
+var _ ports.ParameterValidator = FileParameterResolver{}
@@ -0,0 +1,73 @@
+package parameterResolver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func TestMakeFileParameterResolver(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "fileParameterResolverTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "yaml", file: "params.yaml", content: "NAME: my-service\nPORT: 8080\nTLS: true\n", want: map[string]string{"NAME": "my-service", "PORT": "8080", "TLS": "true"}},
+		{name: "yml", file: "params.yml", content: "NAME: my-service\n", want: map[string]string{"NAME": "my-service"}},
+		{name: "yaml numbers as written", file: "params.yaml", content: "VERSION: 1.10\nREPLICAS: 1000000\nEMPTY:\n", want: map[string]string{"VERSION": "1.10", "REPLICAS": "1000000", "EMPTY": ""}},
+		{name: "nested yaml", file: "params.yaml", content: "NAME:\n  first: my-service\n", wantErr: true},
+		{name: "json", file: "params.json", content: `{"NAME": "my-service", "PORT": 8080}`, want: map[string]string{"NAME": "my-service", "PORT": "8080"}},
+		{name: "json numbers as written", file: "params.json", content: `{"REPLICAS": 1000000, "RATIO": 0.50, "TLS": true, "EMPTY": null}`, want: map[string]string{"REPLICAS": "1000000", "RATIO": "0.50", "TLS": "true", "EMPTY": ""}},
+		{name: "invalid json", file: "params.json", content: `{"NAME": `, wantErr: true},
+		{name: "dotenv", file: ".env", content: "# service\nNAME=my-service\n\nexport PORT = 8080\nGREETING=\"hello\\nworld\"\nQUOTED='a=b'\n", want: map[string]string{"NAME": "my-service", "PORT": "8080", "GREETING": "hello\nworld", "QUOTED": "a=b"}},
+		{name: "dotenv without name", file: "params.env", content: "=value\n", wantErr: true},
+		{name: "invalid dotenv", file: "params.env", content: "NAME\n", wantErr: true},
+		{name: "missing file", file: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "missing.yaml")
+			if tt.file != "" {
+				path = filepath.Join(tempDir, tt.file)
+				ioutil.WriteFile(path, []byte(tt.content), 0644)
+				defer os.Remove(path)
+			}
+			got, err := MakeFileParameterResolver(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MakeFileParameterResolver() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got.parameters, tt.want) {
+				t.Errorf("MakeFileParameterResolver() parameters = %v, want %v", got.parameters, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileParameterResolver_Validate(t *testing.T) {
+	fpr := FileParameterResolver{path: "params.yaml", parameters: map[string]string{"NAME": "my-service", "PORT": "8080"}}
+	tests := []struct {
+		name     string
+		declared []ports.BrickParameters
+		wantErr  bool
+	}{
+		{name: "all declared", declared: []ports.BrickParameters{{Name: "NAME"}, {Name: "PORT"}, {Name: "HOST"}}, wantErr: false},
+		{name: "unknown parameter", declared: []ports.BrickParameters{{Name: "NAME"}, {Name: "HOST"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := fpr.Validate(tt.declared); (err != nil) != tt.wantErr {
+				t.Errorf("FileParameterResolver.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,41 +2,47 @@ package parameterResolver
 
 import (
 	"bufio"
-	"fmt"
-	"io"
 	"os"
-	"strings"
 
 	"github.com/seboste/sapper/ports"
 )
 
+// InteractiveParameterResolver asks the user for parameters on the terminal
 type InteractiveParameterResolver struct {
+	prompter prompter
 }
 
-func resolve(rd io.Reader, wr io.Writer, name string, defaultValue string) string {
-	reader := bufio.NewReader(rd)
-	value := ""
-	if defaultValue != "" {
-		fmt.Fprintf(wr, "Enter value for parameter %s or press enter for default %s: ", name, defaultValue)
-		value, _ = reader.ReadString('\n')
-		value = strings.TrimRight(value, "\n")
-		if value == "" {
-			value = defaultValue
-		}
-	} else {
-		for value == "" {
-			fmt.Fprintf(wr, "Enter value for parameter %s: ", name)
-			value, _ = reader.ReadString('\n')
-			value = strings.TrimRight(value, "\n")
-		}
-	}
-	return value
+// MakeInteractiveParameterResolver creates a resolver that reads from stdin. All questions share one reader so that input that has
+// been buffered while answering one question is not lost for the next.
+func MakeInteractiveParameterResolver() InteractiveParameterResolver {
+	return InteractiveParameterResolver{prompter: prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout, term: sttyTerminal{}}}
 }
 
-var _ ports.ParameterResolver = InteractiveParameterResolver{}
+// StdinIsTerminal reports whether the user can be asked for input, i.e. stdin is not redirected from a file or a pipe
+func StdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) { //the null device is a character device, too
+		return false
+	}
+	return true
+}
 
+// Resolve asks for a single parameter. The default value is used if there is no more input.
 func (ipr InteractiveParameterResolver) Resolve(name string, defaultValue string) string {
-	return resolve(os.Stdin, os.Stdout, name, defaultValue)
+	value, err := ipr.prompter.ask(ports.BrickParameters{Name: name}, defaultValue)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// Prompt asks for all parameters without a value grouped by brick and lets the user review the values before they are used
+func (ipr InteractiveParameterResolver) Prompt(requests []ports.ParameterRequest) (map[string]string, error) {
+	return ipr.prompter.prompt(requests)
 }
 
 var _ ports.ParameterResolver = InteractiveParameterResolver{}
+var _ ports.ParameterPrompter = InteractiveParameterResolver{}
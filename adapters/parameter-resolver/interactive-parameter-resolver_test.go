@@ -1,14 +1,14 @@
 package parameterResolver
 
 import (
+	"bufio"
 	"bytes"
-	"io"
 	"testing"
 )
 
-func Test_resolve(t *testing.T) {
+func TestInteractiveParameterResolver_Resolve(t *testing.T) {
 	type args struct {
-		rd           io.Reader
+		input        string
 		name         string
 		defaultValue string
 	}
@@ -18,20 +18,37 @@ func Test_resolve(t *testing.T) {
 		wantResult string
 		wantOutput string
 	}{
-		{name: "some parameter", args: args{rd: bytes.NewBufferString("value\n"), name: "param_1"}, wantResult: "value", wantOutput: "Enter value for parameter param_1: "},
-		{name: "some parameter with default", args: args{rd: bytes.NewBufferString("value\n"), name: "param_1", defaultValue: "defaultValue"}, wantResult: "value", wantOutput: "Enter value for parameter param_1 or press enter for default defaultValue: "},
-		{name: "pressing enter with default", args: args{rd: bytes.NewBufferString("\n"), name: "param_1", defaultValue: "defaultValue"}, wantResult: "defaultValue", wantOutput: "Enter value for parameter param_1 or press enter for default defaultValue: "},
-		{name: "pressing enter without default", args: args{rd: bytes.NewBufferString("\n\nvalue\n"), name: "param_1"}, wantResult: "value", wantOutput: "Enter value for parameter param_1: Enter value for parameter param_1: Enter value for parameter param_1: "},
+		{name: "some parameter", args: args{input: "value\n", name: "param_1"}, wantResult: "value", wantOutput: "  param_1: "},
+		{name: "some parameter with default", args: args{input: "value\n", name: "param_1", defaultValue: "defaultValue"}, wantResult: "value", wantOutput: "  param_1 [defaultValue]: "},
+		{name: "pressing enter with default", args: args{input: "\n", name: "param_1", defaultValue: "defaultValue"}, wantResult: "defaultValue", wantOutput: "  param_1 [defaultValue]: "},
+		{name: "pressing enter without default", args: args{input: "\n\nvalue\n", name: "param_1"}, wantResult: "value", wantOutput: "  param_1:   param_1:   param_1: "},
+		{name: "no input without default", args: args{input: "", name: "param_1"}, wantResult: "", wantOutput: "  param_1: "},
+		{name: "empty lines until end of input", args: args{input: "\n", name: "param_1"}, wantResult: "", wantOutput: "  param_1:   param_1: "},
+		{name: "last line without newline", args: args{input: "value", name: "param_1"}, wantResult: "value", wantOutput: "  param_1: "},
+		{name: "no input with default", args: args{input: "", name: "param_1", defaultValue: "defaultValue"}, wantResult: "defaultValue", wantOutput: "  param_1 [defaultValue]: "},
 	}
 	for _, tt := range tests {
-		var b bytes.Buffer
 		t.Run(tt.name, func(t *testing.T) {
-			if got := resolve(tt.args.rd, &b, tt.args.name, tt.args.defaultValue); got != tt.wantResult {
-				t.Errorf("resolve() = %v, want %v", got, tt.wantResult)
+			var b bytes.Buffer
+			calls := []string{}
+			ipr := InteractiveParameterResolver{prompter: prompter{in: bufio.NewReader(bytes.NewBufferString(tt.args.input)), out: &b, term: fakeTerminal{calls: &calls}}}
+			if got := ipr.Resolve(tt.args.name, tt.args.defaultValue); got != tt.wantResult {
+				t.Errorf("InteractiveParameterResolver.Resolve() = %v, want %v", got, tt.wantResult)
 			}
 			if b.String() != tt.wantOutput {
-				t.Errorf("resolve() output = %s, want %s", b.String(), tt.wantOutput)
+				t.Errorf("InteractiveParameterResolver.Resolve() output = %q, want %q", b.String(), tt.wantOutput)
 			}
 		})
 	}
 }
+
+func TestInteractiveParameterResolver_SharedInput(t *testing.T) {
+	calls := []string{}
+	ipr := InteractiveParameterResolver{prompter: prompter{in: bufio.NewReader(bytes.NewBufferString("first\nsecond\n")), out: &bytes.Buffer{}, term: fakeTerminal{calls: &calls}}}
+	if got := ipr.Resolve("A", ""); got != "first" {
+		t.Errorf("InteractiveParameterResolver.Resolve() = %v, want %v", got, "first")
+	}
+	if got := ipr.Resolve("B", ""); got != "second" {
+		t.Errorf("InteractiveParameterResolver.Resolve() of the next parameter = %v, want %v", got, "second")
+	}
+}
@@ -0,0 +1,330 @@
+package parameterResolver
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+)
+
+var ErrAborted = errors.New("aborted by user")
+
+const hiddenValue = "********"
+
+// terminal switches the mode of the terminal the user is typing in. The returned function restores the previous mode.
+type terminal interface {
+	hideInput() (func(), error) //line by line input without echo
+	readKeys() (func(), error)  //key by key input without echo, e.g. for selecting with arrow keys
+}
+
+// sttyTerminal switches the mode of the terminal attached to stdin by calling stty. Ctrl+C is read as a key
+// instead of terminating sapper so that the previous mode can always be restored.
+type sttyTerminal struct{}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func (sttyTerminal) setMode(args ...string) (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(args...); err != nil {
+		return nil, err
+	}
+	return func() { stty(state) }, nil
+}
+
+func (t sttyTerminal) hideInput() (func(), error) {
+	return t.setMode("-echo", "-isig")
+}
+
+func (t sttyTerminal) readKeys() (func(), error) {
+	return t.setMode("-icanon", "-echo", "-isig", "min", "1")
+}
+
+type key int
+
+const (
+	otherKey key = iota
+	upKey
+	downKey
+	enterKey
+	interruptKey
+)
+
+// prompter asks the user for the values of parameters grouped by brick and lets the user review them
+type prompter struct {
+	in   *bufio.Reader
+	out  io.Writer
+	term terminal
+}
+
+// prompt asks for all requests without a value. If the input ends before all values are known, the known values are returned
+// without review.
+func (p prompter) prompt(requests []ports.ParameterRequest) (map[string]string, error) {
+	values := map[string]string{}
+	asked := []ports.ParameterRequest{}
+	for _, r := range requests {
+		if r.Value != "" {
+			values[r.Parameter.Name] = r.Value
+		} else {
+			asked = append(asked, r)
+		}
+	}
+	if len(asked) == 0 {
+		return values, nil
+	}
+
+	for {
+		brick := ""
+		for _, r := range asked {
+			if r.Brick != brick && r.Brick != "" {
+				fmt.Fprintf(p.out, "\nParameters of brick %s:\n", r.Brick)
+			}
+			brick = r.Brick
+
+			defaultValue := r.Parameter.Default
+			if v, ok := values[r.Parameter.Name]; ok { //edited after review => suggest the previous value
+				defaultValue = v
+			}
+			value, err := p.ask(r.Parameter, defaultValue)
+			if err == io.EOF {
+				return values, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[r.Parameter.Name] = value
+		}
+
+		edit, err := p.review(requests, values)
+		if err != nil {
+			return nil, err
+		}
+		if !edit {
+			return values, nil
+		}
+	}
+}
+
+// ask asks for the value of a parameter until a valid value is entered. io.EOF is returned if there is no more input.
+func (p prompter) ask(parameter ports.BrickParameters, defaultValue string) (string, error) {
+	if parameter.Description != "" {
+		fmt.Fprintf(p.out, "  %s\n", parameter.Description)
+	}
+	if len(parameter.Choices) > 0 {
+		return p.choose(parameter, defaultValue)
+	}
+
+	for {
+		shownDefault := defaultValue
+		if parameter.Secret && shownDefault != "" {
+			shownDefault = hiddenValue
+		}
+		if shownDefault != "" {
+			fmt.Fprintf(p.out, "  %s [%s]: ", parameter.Name, shownDefault)
+		} else {
+			fmt.Fprintf(p.out, "  %s: ", parameter.Name)
+		}
+
+		value, err := p.readLine(parameter.Secret)
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			value = defaultValue
+		}
+		if value == "" {
+			continue
+		}
+		if err := parameter.Validate(value); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+// readLine reads a line. The input is not shown if hidden is set and the terminal supports it. Otherwise, the user is warned.
+func (p prompter) readLine(hidden bool) (string, error) {
+	if hidden {
+		if restore, err := p.term.hideInput(); err == nil {
+			defer fmt.Fprintln(p.out) //the line break has not been echoed
+			defer restore()
+		} else {
+			fmt.Fprint(p.out, "(warning: the input cannot be hidden and will be shown) ")
+		}
+	}
+	line, err := p.in.ReadString('\n')
+	if strings.ContainsRune(line, 3) { //Ctrl+C while the terminal does not generate signals
+		return "", ErrAborted
+	}
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p prompter) readKey() (key, error) {
+	b, err := p.in.ReadByte()
+	if err != nil {
+		return otherKey, err
+	}
+	switch b {
+	case '\r', '\n':
+		return enterKey, nil
+	case 3:
+		return interruptKey, nil
+	case 'k':
+		return upKey, nil
+	case 'j':
+		return downKey, nil
+	case 0x1b: //escape sequence of the arrow keys, e.g. ESC [ A
+		if b, err := p.in.ReadByte(); err != nil || (b != '[' && b != 'O') {
+			return otherKey, nil
+		}
+		b, _ := p.in.ReadByte()
+		switch b {
+		case 'A':
+			return upKey, nil
+		case 'B':
+			return downKey, nil
+		}
+	}
+	return otherKey, nil
+}
+
+func (p prompter) renderChoices(choices []string, selected int, redraw bool) {
+	if redraw {
+		fmt.Fprintf(p.out, "\x1b[%dA", len(choices)) //move the cursor back to the first choice
+	}
+	for i, c := range choices {
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+		fmt.Fprintf(p.out, "\r\x1b[K    %s%s\n", marker, c)
+	}
+}
+
+// choose lets the user select one of the parameter's choices with the arrow keys. If the terminal does not support reading
+// single keys, the user enters the number of a choice instead.
+func (p prompter) choose(parameter ports.BrickParameters, defaultValue string) (string, error) {
+	selected := 0
+	for i, c := range parameter.Choices {
+		if c == defaultValue {
+			selected = i
+		}
+	}
+
+	restore, err := p.term.readKeys()
+	if err != nil {
+		return p.chooseByNumber(parameter, selected)
+	}
+	defer restore()
+
+	fmt.Fprintf(p.out, "  %s (use arrow keys and press enter to select):\n", parameter.Name)
+	p.renderChoices(parameter.Choices, selected, false)
+	for {
+		k, err := p.readKey()
+		if err != nil {
+			return "", err
+		}
+		switch k {
+		case upKey:
+			if selected > 0 {
+				selected--
+			}
+		case downKey:
+			if selected < len(parameter.Choices)-1 {
+				selected++
+			}
+		case enterKey:
+			return parameter.Choices[selected], nil
+		case interruptKey:
+			return "", ErrAborted
+		default:
+			continue
+		}
+		p.renderChoices(parameter.Choices, selected, true)
+	}
+}
+
+func (p prompter) chooseByNumber(parameter ports.BrickParameters, selected int) (string, error) {
+	for i, c := range parameter.Choices {
+		fmt.Fprintf(p.out, "    %d) %s\n", i+1, c)
+	}
+	for {
+		fmt.Fprintf(p.out, "  %s [%d]: ", parameter.Name, selected+1)
+		line, err := p.readLine(false)
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return parameter.Choices[selected], nil
+		}
+		if i, err := strconv.Atoi(line); err == nil && i >= 1 && i <= len(parameter.Choices) {
+			return parameter.Choices[i-1], nil
+		}
+		for _, c := range parameter.Choices {
+			if c == line {
+				return c, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  invalid choice '%s'. Enter a number between 1 and %d\n", line, len(parameter.Choices))
+	}
+}
+
+// review shows the values of all parameters and asks whether to proceed. It returns true if the user wants to edit the values.
+func (p prompter) review(requests []ports.ParameterRequest, values map[string]string) (bool, error) {
+	width := 0
+	for _, r := range requests {
+		if len(r.Parameter.Name) > width {
+			width = len(r.Parameter.Name)
+		}
+	}
+
+	fmt.Fprintln(p.out, "\nReview parameters:")
+	brick := ""
+	for i, r := range requests {
+		if i == 0 || r.Brick != brick {
+			if r.Brick != "" {
+				fmt.Fprintf(p.out, "  %s\n", r.Brick)
+			}
+			brick = r.Brick
+		}
+		value := values[r.Parameter.Name]
+		if r.Parameter.Secret {
+			value = hiddenValue
+		}
+		fmt.Fprintf(p.out, "    %-*s = %s\n", width, r.Parameter.Name, value)
+	}
+
+	for {
+		fmt.Fprint(p.out, "Proceed? [Y]es, [n]o, [e]dit: ")
+		line, err := p.readLine(false)
+		if err != nil {
+			return false, fmt.Errorf("parameters have not been confirmed: %w", ErrAborted)
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "", "y", "yes":
+			return false, nil
+		case "n", "no":
+			return false, ErrAborted
+		case "e", "edit":
+			return true, nil
+		}
+	}
+}
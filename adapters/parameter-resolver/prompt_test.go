@@ -0,0 +1,177 @@
+package parameterResolver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type fakeTerminal struct {
+	unsupported bool
+	calls       *[]string
+}
+
+func (t fakeTerminal) setMode(mode string) (func(), error) {
+	if t.unsupported {
+		return nil, errors.New("stty not available")
+	}
+	*t.calls = append(*t.calls, mode)
+	return func() { *t.calls = append(*t.calls, "restore") }, nil
+}
+
+func (t fakeTerminal) hideInput() (func(), error) {
+	return t.setMode("hideInput")
+}
+
+func (t fakeTerminal) readKeys() (func(), error) {
+	return t.setMode("readKeys")
+}
+
+func Test_prompter_prompt(t *testing.T) {
+	port := ports.BrickParameters{Name: "PORT", Default: "8080", Description: "Port the service listens on", Pattern: "[0-9]+"}
+	db := ports.BrickParameters{Name: "DB", Default: "postgres", Choices: []string{"postgres", "mysql", "sqlite"}}
+	token := ports.BrickParameters{Name: "TOKEN", Secret: true}
+
+	tests := []struct {
+		name            string
+		requests        []ports.ParameterRequest
+		input           string
+		unsupported     bool
+		want            map[string]string
+		wantErr         error
+		wantOutput      []string
+		wantNotInOutput []string
+		wantCalls       []string
+	}{
+		{name: "nothing to ask",
+			requests:  []ports.ParameterRequest{{Brick: "tmpl", Parameter: ports.BrickParameters{Name: "NAME"}, Value: "svc"}},
+			input:     "",
+			want:      map[string]string{"NAME": "svc"},
+			wantCalls: []string{},
+		},
+		{name: "grouped by brick with descriptions",
+			requests: []ports.ParameterRequest{
+				{Brick: "tmpl", Parameter: ports.BrickParameters{Name: "NAME"}, Value: "svc"},
+				{Brick: "tmpl", Parameter: port},
+				{Brick: "repo", Parameter: ports.BrickParameters{Name: "HOST"}},
+			},
+			input:      "\nlocalhost\ny\n",
+			want:       map[string]string{"NAME": "svc", "PORT": "8080", "HOST": "localhost"},
+			wantOutput: []string{"Parameters of brick tmpl:\n  Port the service listens on\n  PORT [8080]: ", "Parameters of brick repo:\n  HOST: ", "Review parameters:\n  tmpl\n    NAME = svc\n    PORT = 8080\n  repo\n    HOST = localhost\n"},
+			wantCalls:  []string{},
+		},
+		{name: "empty value without default is asked again",
+			requests:   []ports.ParameterRequest{{Brick: "repo", Parameter: ports.BrickParameters{Name: "HOST"}}},
+			input:      "\nlocalhost\n\n",
+			want:       map[string]string{"HOST": "localhost"},
+			wantOutput: []string{"  HOST:   HOST: "},
+			wantCalls:  []string{},
+		},
+		{name: "invalid value is asked again",
+			requests:   []ports.ParameterRequest{{Brick: "tmpl", Parameter: port}},
+			input:      "http\n8081\n\n",
+			want:       map[string]string{"PORT": "8081"},
+			wantOutput: []string{"invalid value 'http' for parameter PORT. Must match [0-9]+\n  PORT [8080]: "},
+			wantCalls:  []string{},
+		},
+		{name: "secret input is hidden",
+			requests:        []ports.ParameterRequest{{Brick: "repo", Parameter: token}},
+			input:           "s3cr3t\n\n",
+			want:            map[string]string{"TOKEN": "s3cr3t"},
+			wantOutput:      []string{"TOKEN = " + hiddenValue},
+			wantNotInOutput: []string{"s3cr3t"},
+			wantCalls:       []string{"hideInput", "restore"},
+		},
+		{name: "choice selected with arrow keys",
+			requests:   []ports.ParameterRequest{{Brick: "repo", Parameter: db}},
+			input:      "\x1b[B\x1b[B\x1b[Bj\x1b[A\r\n",
+			want:       map[string]string{"DB": "mysql"},
+			wantOutput: []string{"DB (use arrow keys and press enter to select):\n", "    > postgres\n", "    > mysql\n", "    > sqlite\n", "DB = mysql"},
+			wantCalls:  []string{"readKeys", "restore"},
+		},
+		{name: "choice aborted with ctrl+c",
+			requests:  []ports.ParameterRequest{{Brick: "repo", Parameter: db}},
+			input:     "\x1b[B\x03",
+			wantErr:   ErrAborted,
+			wantCalls: []string{"readKeys", "restore"},
+		},
+		{name: "choice selected by number without terminal support",
+			requests:    []ports.ParameterRequest{{Brick: "repo", Parameter: db}},
+			input:       "4\n3\n\n",
+			unsupported: true,
+			want:        map[string]string{"DB": "sqlite"},
+			wantOutput:  []string{"    1) postgres\n    2) mysql\n    3) sqlite\n  DB [1]: ", "invalid choice '4'"},
+			wantCalls:   []string{},
+		},
+		{name: "secret input is visible with a warning without terminal support",
+			requests:    []ports.ParameterRequest{{Brick: "repo", Parameter: token}},
+			input:       "s3cr3t\n\n",
+			unsupported: true,
+			want:        map[string]string{"TOKEN": "s3cr3t"},
+			wantOutput:  []string{"  TOKEN: (warning: the input cannot be hidden and will be shown) "},
+			wantCalls:   []string{},
+		},
+		{name: "rejected on review",
+			requests:  []ports.ParameterRequest{{Brick: "repo", Parameter: ports.BrickParameters{Name: "HOST"}}},
+			input:     "localhost\nn\n",
+			wantErr:   ErrAborted,
+			wantCalls: []string{},
+		},
+		{name: "edited on review",
+			requests:   []ports.ParameterRequest{{Brick: "repo", Parameter: ports.BrickParameters{Name: "HOST"}}},
+			input:      "localhost\ne\n\ny\n",
+			want:       map[string]string{"HOST": "localhost"},
+			wantOutput: []string{"Proceed? [Y]es, [n]o, [e]dit: \nParameters of brick repo:\n  HOST [localhost]: "},
+			wantCalls:  []string{},
+		},
+		{name: "end of input on review",
+			requests:  []ports.ParameterRequest{{Brick: "repo", Parameter: ports.BrickParameters{Name: "HOST"}}},
+			input:     "localhost\n",
+			wantErr:   ErrAborted,
+			wantCalls: []string{},
+		},
+		{name: "end of input while asking",
+			requests: []ports.ParameterRequest{
+				{Brick: "repo", Parameter: ports.BrickParameters{Name: "HOST"}},
+				{Brick: "repo", Parameter: ports.BrickParameters{Name: "USER"}},
+			},
+			input:           "localhost\n",
+			want:            map[string]string{"HOST": "localhost"},
+			wantNotInOutput: []string{"Review parameters"},
+			wantCalls:       []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			calls := []string{}
+			p := prompter{in: bufio.NewReader(strings.NewReader(tt.input)), out: &out, term: fakeTerminal{unsupported: tt.unsupported, calls: &calls}}
+
+			got, err := p.prompt(tt.requests)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("prompter.prompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prompter.prompt() = %v, want %v", got, tt.want)
+			}
+			for _, o := range tt.wantOutput {
+				if !strings.Contains(out.String(), o) {
+					t.Errorf("prompter.prompt() output = %q, want it to contain %q", out.String(), o)
+				}
+			}
+			for _, o := range tt.wantNotInOutput {
+				if strings.Contains(out.String(), o) {
+					t.Errorf("prompter.prompt() output = %q, must not contain %q", out.String(), o)
+				}
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("prompter.prompt() terminal calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -7,14 +7,21 @@ import (
 )
 
 type SapperParameterResolver struct {
-	cpr upr.CompoundParameterResolver
+	cpr      upr.CompoundParameterResolver
+	prompter ports.ParameterPrompter //asks the user for the parameters that cannot be resolved otherwise. Optional
 }
 
 func RegisterSapperParameterResolver(flags *pflag.FlagSet) {
 	flags.StringArrayP("parameter", "p", []string{}, "Sets parameters of the service (Example: '-p PARAM_NAME=value').")
+	flags.String("parameters-file", "", "Reads parameters of the service from a YAML, JSON, or dotenv (e.g. .env) file. Parameters passed with -p take precedence.")
+	flags.String("parameters-env-prefix", upr.DefaultEnvPrefix, "Reads parameters of the service from environment variables with this prefix (Example: 'SAPPER_PARAM_PORT=8081'). An empty prefix disables them.")
+	flags.Bool("no-input", false, "Never asks for parameters. Fails listing all parameters that could not be resolved instead. Enabled automatically if stdin is not a terminal.")
 }
 
-func MakeSapperParameterResolver(flags *pflag.FlagSet, name string) (SapperParameterResolver, error) {
+// MakeSapperParameterResolver creates a resolver that takes parameters from the command line, the service name, the parameters file,
+// environment variables, the default values of the configuration, and finally asks the user unless --no-input is set or
+// stdin is not a terminal
+func MakeSapperParameterResolver(flags *pflag.FlagSet, name string, defaults map[string]string) (SapperParameterResolver, error) {
 
 	resolver := []ports.ParameterResolver{}
 
@@ -26,15 +33,39 @@ func MakeSapperParameterResolver(flags *pflag.FlagSet, name string) (SapperParam
 	}
 	resolver = append(resolver, clipr)
 
-	//2. set name as fixed parameter
+	//2. set name as fixed parameter. It must not be changed by the parameters file or the environment
 	if name != "" {
 		resolver = append(resolver, upr.MakeMapBasedParameterResolver(map[string]string{"NAME": name}))
 	}
 
-	//3. ask user for parameters if other methods failed
-	resolver = append(resolver, InteractiveParameterResolver{})
+	//3. read from the parameters file
+	if path, _ := flags.GetString("parameters-file"); path != "" {
+		fpr, err := MakeFileParameterResolver(path)
+		if err != nil {
+			return SapperParameterResolver{}, err
+		}
+		resolver = append(resolver, fpr)
+	}
+
+	//4. read from environment variables
+	if prefix, _ := flags.GetString("parameters-env-prefix"); prefix != "" {
+		resolver = append(resolver, upr.MakeEnvParameterResolver(prefix))
+	}
+
+	//5. use the default values of the configuration
+	if len(defaults) > 0 {
+		resolver = append(resolver, upr.MakeMapBasedParameterResolver(defaults))
+	}
+
+	//6. ask user for parameters if other methods failed. Without input, fall back to the bricks' default values
+	var prompter ports.ParameterPrompter
+	if noInput, _ := flags.GetBool("no-input"); !noInput && StdinIsTerminal() {
+		prompter = MakeInteractiveParameterResolver()
+	} else {
+		resolver = append(resolver, upr.DefaultParameterResolver{})
+	}
 
-	return SapperParameterResolver{cpr: upr.MakeCompoundParameterResolver(resolver)}, nil
+	return SapperParameterResolver{cpr: upr.MakeCompoundParameterResolver(resolver), prompter: prompter}, nil
 
 }
 
@@ -42,4 +73,17 @@ func (r SapperParameterResolver) Resolve(key string, defaultValue string) string
 	return r.cpr.Resolve(key, defaultValue) //delegate
 }
 
+func (r SapperParameterResolver) Validate(declared []ports.BrickParameters) error {
+	return r.cpr.Validate(declared) //delegate
+}
+
+func (r SapperParameterResolver) Prompt(requests []ports.ParameterRequest) (map[string]string, error) {
+	if r.prompter == nil {
+		return r.cpr.Prompt(requests)
+	}
+	return r.prompter.Prompt(requests)
+}
+
 var _ ports.ParameterResolver = SapperParameterResolver{}
+var _ ports.ParameterValidator = SapperParameterResolver{}
+var _ ports.ParameterPrompter = SapperParameterResolver{}
@@ -0,0 +1,92 @@
+package parameterResolver
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestSapperParameterResolver_Resolve(t *testing.T) {
+	t.Setenv("SAPPER_PARAM_PORT", "9090")
+	t.Setenv("SAPPER_PARAM_TAG", "env-tag")
+	t.Setenv("SAPPER_PARAM_EMAIL", "ci@example.com")
+	t.Setenv("SAPPER_PARAM_NAME", "env-service")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterSapperParameterResolver(flags)
+	flags.Parse([]string{"-p", "PORT=8080", "-p", "REGISTRY=cli.example.com"})
+
+	r, err := MakeSapperParameterResolver(flags, "my-service", map[string]string{"REGISTRY": "config.example.com", "EMAIL": "team@example.com", "NAME": "other"})
+	if err != nil {
+		t.Fatalf("MakeSapperParameterResolver() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "command line takes precedence over configuration", key: "REGISTRY", want: "cli.example.com"},
+		{name: "service name takes precedence over environment and configuration", key: "NAME", want: "my-service"},
+		{name: "command line takes precedence over environment", key: "PORT", want: "8080"},
+		{name: "environment", key: "TAG", want: "env-tag"},
+		{name: "environment takes precedence over configuration", key: "EMAIL", want: "ci@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Resolve(tt.key, ""); got != tt.want {
+				t.Errorf("SapperParameterResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSapperParameterResolver_EnvPrefix(t *testing.T) {
+	t.Setenv("SAPPER_PARAM_PORT", "9090")
+	t.Setenv("CI_PORT", "7070")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "default prefix", args: []string{}, want: "9090"},
+		{name: "custom prefix", args: []string{"--parameters-env-prefix", "CI_"}, want: "7070"},
+		{name: "disabled", args: []string{"--parameters-env-prefix", ""}, want: "default-port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			RegisterSapperParameterResolver(flags)
+			flags.Parse(tt.args)
+
+			r, err := MakeSapperParameterResolver(flags, "", map[string]string{"PORT": "default-port"})
+			if err != nil {
+				t.Fatalf("MakeSapperParameterResolver() error = %v", err)
+			}
+			if got := r.Resolve("PORT", ""); got != tt.want {
+				t.Errorf("SapperParameterResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSapperParameterResolver_NoInput(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterSapperParameterResolver(flags)
+	flags.Parse([]string{"--no-input", "-p", "PORT=8080"})
+
+	r, err := MakeSapperParameterResolver(flags, "", nil)
+	if err != nil {
+		t.Fatalf("MakeSapperParameterResolver() error = %v", err)
+	}
+	if got := r.Resolve("PORT", ""); got != "8080" {
+		t.Errorf("SapperParameterResolver.Resolve() = %v, want %v", got, "8080")
+	}
+	if got := r.Resolve("MISSING", ""); got != "" {
+		t.Errorf("SapperParameterResolver.Resolve() = %v, want empty value", got)
+	}
+	if got := r.Resolve("MISSING", "default"); got != "default" {
+		t.Errorf("SapperParameterResolver.Resolve() = %v, want %v", got, "default")
+	}
+}
@@ -1,17 +1,153 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/seboste/sapper/ports"
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	envSecretReference  = ports.EnvSecretReferencePrefix
+	fileSecretReference = "file:"
+)
+
 type FileSystemServicePersistence struct {
 	DependencyReader ports.ServicePackageDependencyReader
 }
 
+// secretsFilePath returns the path of the secrets file a reference refers to or "" if the reference does not refer to a file
+func secretsFilePath(servicePath string, reference string) (string, error) {
+	if strings.HasPrefix(reference, envSecretReference) {
+		return "", nil
+	}
+	if !strings.HasPrefix(reference, fileSecretReference) {
+		return "", fmt.Errorf("invalid secret reference '%s'. Must be 'env:VARIABLE' or 'file:PATH'", reference)
+	}
+	path := strings.TrimPrefix(reference, fileSecretReference)
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(servicePath, path), nil
+}
+
+func readSecretsFile(path string) (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+	return secrets, nil
+}
+
+// ignoreInGit makes sure that the .gitignore next to a file ignores it
+func ignoreInGit(path string) error {
+	gitignorePath := filepath.Join(filepath.Dir(path), ".gitignore")
+	entry := "/" + filepath.Base(path)
+	content, err := ioutil.ReadFile(gitignorePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == entry {
+			return nil
+		}
+	}
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	return ioutil.WriteFile(gitignorePath, append(content, []byte(entry+"\n")...), 0644)
+}
+
+// loadSecrets adds the values of the secret parameters to the parameters. Secrets without a value are left out so that they are
+// resolved again.
+func loadSecrets(s *ports.Service) error {
+	files := map[string]map[string]string{}
+	for name, reference := range s.Secrets {
+		value := ""
+		if strings.HasPrefix(reference, envSecretReference) {
+			value = os.Getenv(strings.TrimPrefix(reference, envSecretReference))
+		} else {
+			path, err := secretsFilePath(s.Path, reference)
+			if err != nil {
+				return fmt.Errorf("secret parameter %s: %w", name, err)
+			}
+			if _, ok := files[path]; !ok {
+				if files[path], err = readSecretsFile(path); err != nil {
+					return err
+				}
+			}
+			value = files[path][name]
+		}
+		if value == "" {
+			continue
+		}
+		if s.Parameters == nil {
+			s.Parameters = map[string]string{}
+		}
+		s.Parameters[name] = value
+	}
+	return nil
+}
+
+// saveSecrets writes the values of the secret parameters that refer to files into these files
+func saveSecrets(s ports.Service) error {
+	files := map[string]map[string]string{}
+	for name, reference := range s.Secrets {
+		path, err := secretsFilePath(s.Path, reference)
+		if err != nil {
+			return fmt.Errorf("secret parameter %s: %w", name, err)
+		}
+		value, ok := s.Parameters[name]
+		if path == "" || !ok {
+			continue
+		}
+		if _, ok := files[path]; !ok {
+			if files[path], err = readSecretsFile(path); err != nil {
+				return err
+			}
+		}
+		files[path][name] = value
+	}
+
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		data, err := yaml.Marshal(files[path])
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		if err := ignoreInGit(path); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (fsp FileSystemServicePersistence) Load(path string) (ports.Service, error) {
 	s := ports.Service{Path: path}
 
@@ -26,6 +162,10 @@ func (fsp FileSystemServicePersistence) Load(path string) (ports.Service, error)
 		return s, err
 	}
 
+	if err := loadSecrets(&s); err != nil {
+		return s, err
+	}
+
 	s.Dependencies, err = fsp.DependencyReader.ReadFromService(s)
 	if err != nil {
 		return s, err
@@ -36,6 +176,19 @@ func (fsp FileSystemServicePersistence) Load(path string) (ports.Service, error)
 
 func (fsp FileSystemServicePersistence) Save(s ports.Service) error {
 
+	if err := saveSecrets(s); err != nil {
+		return err
+	}
+
+	//the values of secret parameters are never written to the sapperfile
+	parameters := map[string]string{}
+	for k, v := range s.Parameters {
+		if _, secret := s.Secrets[k]; !secret {
+			parameters[k] = v
+		}
+	}
+	s.Parameters = parameters
+
 	yamlData, err := yaml.Marshal(s)
 	if err != nil {
 		return err
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type noDependencies struct{}
+
+func (noDependencies) ReadFromService(s ports.Service) ([]ports.PackageDependency, error) {
+	return nil, nil
+}
+
+func TestFileSystemServicePersistence_Secrets(t *testing.T) {
+	serviceDir, _ := ioutil.TempDir("", "servicePersistenceTest*")
+	defer os.RemoveAll(serviceDir) // clean up
+	t.Setenv("MY_SERVICE_TOKEN", "token-from-env")
+
+	fsp := FileSystemServicePersistence{DependencyReader: noDependencies{}}
+	service := ports.Service{
+		Id:         "my-service",
+		Path:       serviceDir,
+		BrickIds:   []ports.BrickDependency{},
+		Parameters: map[string]string{"NAME": "my-service", "DB_PASSWORD": "pw", "TOKEN": "t"},
+		Secrets:    map[string]string{"DB_PASSWORD": ports.DefaultSecretReference, "TOKEN": "env:MY_SERVICE_TOKEN"},
+	}
+	if err := fsp.Save(service); err != nil {
+		t.Fatalf("FileSystemServicePersistence.Save() error = %v", err)
+	}
+
+	sapperfile, _ := ioutil.ReadFile(filepath.Join(serviceDir, "sapperfile.yaml"))
+	for _, secret := range []string{"pw", ": t\n"} {
+		if strings.Contains(string(sapperfile), secret) {
+			t.Errorf("FileSystemServicePersistence.Save() wrote secret %q to the sapperfile:\n%s", secret, sapperfile)
+		}
+	}
+	if secrets, _ := ioutil.ReadFile(filepath.Join(serviceDir, ".sapper", "secrets.yaml")); string(secrets) != "DB_PASSWORD: pw\n" {
+		t.Errorf("FileSystemServicePersistence.Save() secrets file = %q, want %q", string(secrets), "DB_PASSWORD: pw\n")
+	}
+	if info, err := os.Stat(filepath.Join(serviceDir, ".sapper", "secrets.yaml")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("FileSystemServicePersistence.Save() secrets file is not private")
+	}
+	if gitignore, _ := ioutil.ReadFile(filepath.Join(serviceDir, ".sapper", ".gitignore")); string(gitignore) != "/secrets.yaml\n" {
+		t.Errorf("FileSystemServicePersistence.Save() .gitignore = %q, want %q", string(gitignore), "/secrets.yaml\n")
+	}
+	if service.Parameters["DB_PASSWORD"] != "pw" {
+		t.Errorf("FileSystemServicePersistence.Save() modified the parameters of the service")
+	}
+
+	//saving again must neither duplicate the .gitignore entry nor lose values
+	if err := fsp.Save(service); err != nil {
+		t.Fatalf("FileSystemServicePersistence.Save() error = %v", err)
+	}
+	if gitignore, _ := ioutil.ReadFile(filepath.Join(serviceDir, ".sapper", ".gitignore")); string(gitignore) != "/secrets.yaml\n" {
+		t.Errorf("FileSystemServicePersistence.Save() .gitignore = %q, want %q", string(gitignore), "/secrets.yaml\n")
+	}
+
+	got, err := fsp.Load(serviceDir)
+	if err != nil {
+		t.Fatalf("FileSystemServicePersistence.Load() error = %v", err)
+	}
+	want := map[string]string{"NAME": "my-service", "DB_PASSWORD": "pw", "TOKEN": "token-from-env"}
+	if !reflect.DeepEqual(got.Parameters, want) {
+		t.Errorf("FileSystemServicePersistence.Load() parameters = %v, want %v", got.Parameters, want)
+	}
+	if !reflect.DeepEqual(got.Secrets, service.Secrets) {
+		t.Errorf("FileSystemServicePersistence.Load() secrets = %v, want %v", got.Secrets, service.Secrets)
+	}
+
+	//secrets without a value are resolved again later on
+	os.Remove(filepath.Join(serviceDir, ".sapper", "secrets.yaml"))
+	t.Setenv("MY_SERVICE_TOKEN", "")
+	got, err = fsp.Load(serviceDir)
+	if err != nil {
+		t.Fatalf("FileSystemServicePersistence.Load() error = %v", err)
+	}
+	if want := map[string]string{"NAME": "my-service"}; !reflect.DeepEqual(got.Parameters, want) {
+		t.Errorf("FileSystemServicePersistence.Load() parameters = %v, want %v", got.Parameters, want)
+	}
+}
+
+func TestFileSystemServicePersistence_InvalidSecretReference(t *testing.T) {
+	serviceDir, _ := ioutil.TempDir("", "servicePersistenceTest*")
+	defer os.RemoveAll(serviceDir) // clean up
+	ioutil.WriteFile(filepath.Join(serviceDir, "sapperfile.yaml"), []byte("id: my-service\nsecrets:\n    TOKEN: vault:token\n"), 0644)
+
+	fsp := FileSystemServicePersistence{DependencyReader: noDependencies{}}
+	if _, err := fsp.Load(serviceDir); err == nil {
+		t.Errorf("FileSystemServicePersistence.Load() error = nil, want an error")
+	}
+}
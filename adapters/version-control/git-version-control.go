@@ -0,0 +1,74 @@
+package versionControl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type GitVersionControl struct {
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func parseNameStatus(output string) []ports.FileChange {
+	changes := []ports.FileChange{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 2)
+		if len(fields) != 2 || fields[0] == "" {
+			continue
+		}
+		kind := ports.FileModified
+		switch fields[0][0] {
+		case 'A':
+			kind = ports.FileAdded
+		case 'D':
+			kind = ports.FileDeleted
+		}
+		changes = append(changes, ports.FileChange{Path: fields[1], Kind: kind})
+	}
+	return changes
+}
+
+// ChangedFiles returns the committed, uncommitted, and untracked changes in path since the given revision
+func (GitVersionControl) ChangedFiles(path string, since string) ([]ports.FileChange, error) {
+	diff, err := git(path, "diff", "--relative", "--name-status", "--no-renames", since, "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	changes := parseNameStatus(diff)
+
+	untracked, err := git(path, "ls-files", "--others", "--exclude-standard", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(untracked))
+	for scanner.Scan() {
+		if file := scanner.Text(); file != "" {
+			changes = append(changes, ports.FileChange{Path: file, Kind: ports.FileAdded})
+		}
+	}
+	return changes, nil
+}
+
+// FileContent returns the content of file (relative to path) at the given revision
+func (GitVersionControl) FileContent(path string, revision string, file string) (string, error) {
+	return git(path, "show", revision+":./"+file)
+}
+
+var _ ports.VersionControl = GitVersionControl{}
@@ -0,0 +1,34 @@
+package versionControl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func Test_parseNameStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []ports.FileChange
+	}{
+		{name: "empty", output: "", want: []ports.FileChange{}},
+		{name: "all kinds of changes",
+			output: "A\tadapters/new.cpp\nM\tapp/main.cpp\nD\told.txt\nT\tlink\n",
+			want: []ports.FileChange{
+				{Path: "adapters/new.cpp", Kind: ports.FileAdded},
+				{Path: "app/main.cpp", Kind: ports.FileModified},
+				{Path: "old.txt", Kind: ports.FileDeleted},
+				{Path: "link", Kind: ports.FileModified},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNameStatus(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNameStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,15 +3,42 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 
 	parameterResolver "github.com/seboste/sapper/adapters/parameter-resolver"
 	"github.com/seboste/sapper/ports"
+	"github.com/seboste/sapper/utils"
 	"github.com/spf13/cobra"
 )
 
 func Print(b ports.Brick) {
-	fmt.Println(b.Id, b.Version, b.Description)
+	fmt.Println(b.Id, b.Version, b.Kind, b.Description)
+}
+
+// shadowedRemotes returns the names of the remotes whose copies of a brick are not used
+func shadowedRemotes(origins []ports.BrickOrigin) string {
+	if len(origins) <= 1 {
+		return "-"
+	}
+	names := []string{}
+	for _, o := range origins[1:] {
+		names = append(names, o.Remote.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func parseKinds(kind string) ([]ports.BrickKind, error) {
+	if kind == "all" {
+		return ports.BrickKinds, nil
+	}
+	k, ok := ports.ParseBrickKind(kind)
+	if !ok {
+		return nil, fmt.Errorf("invalid brick kind %s. Must be one of template, extension, helper, or all", kind)
+	}
+	return []ports.BrickKind{k}, nil
 }
 
 var brickCmd = &cobra.Command{
@@ -20,8 +47,10 @@ var brickCmd = &cobra.Command{
 }
 
 var addBrickCmd = &cobra.Command{
-	Use:           "add [template]",
+	Use:           "add [brickId[@version constraint]]",
 	Short:         "Adds another building brick to the C++ microservice",
+	Example:       "  sapper brick add repo-postgres\n  sapper brick add repo-postgres@^1.2.0",
+	Annotations:   map[string]string{serviceFolderAnnotation: "service"},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -30,10 +59,13 @@ var addBrickCmd = &cobra.Command{
 		}
 		brickId := args[0]
 		service, _ := cmd.Flags().GetString("service")
-		r, err := parameterResolver.MakeSapperParameterResolver(cmd.Flags(), "")
+		r, err := parameterResolver.MakeSapperParameterResolver(cmd.Flags(), "", configApi.Parameters())
 		if err != nil {
 			return err
 		}
+		if err := remoteApi.UpdateStale(os.Stdout); err != nil {
+			return err
+		}
 		return brickApi.Add(service, brickId, r)
 	},
 }
@@ -53,16 +85,29 @@ var upgradeBrickCmd = &cobra.Command{
 }
 
 var listBrickCmd = &cobra.Command{
-	Use:           "list [template]",
+	Use:           "list [--kind=template|extension|helper|all]",
 	Short:         "Displays information about building bricks",
 	SilenceUsage:  true,
 	SilenceErrors: true,
-	Run: func(cmd *cobra.Command, args []string) {
-		bricks := brickApi.List()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind, _ := cmd.Flags().GetString("kind")
+		kinds, err := parseKinds(kind)
+		if err != nil {
+			return err
+		}
+		allVersions, _ := cmd.Flags().GetBool("all-versions")
+		bricks := brickApi.List(kinds, allVersions)
+		origins, err := brickApi.Origins()
+		if err != nil {
+			return err
+		}
 		fmt.Printf("found a total of %d bricks\n", len(bricks))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tVERSION\tKIND\tSHADOWS\tDESCRIPTION")
 		for _, b := range bricks {
-			Print(b)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", b.Id, b.Version, b.Kind, shadowedRemotes(origins[b.Id]), b.Description)
 		}
+		return w.Flush()
 	},
 }
 
@@ -75,9 +120,14 @@ var searchBrickCmd = &cobra.Command{
 		if len(args) < 1 {
 			return errors.New("Unable to search for building bricks. The search term is missing")
 		}
-		bricks := brickApi.Search(args[0])
+		kind, _ := cmd.Flags().GetString("kind")
+		kinds, err := parseKinds(kind)
+		if err != nil {
+			return err
+		}
+		bricks := brickApi.Search(args[0], kinds)
 		fmt.Printf("found a total of %d bricks\n", len(bricks))
-		for _, b := range brickApi.Search(args[0]) {
+		for _, b := range bricks {
 			Print(b)
 		}
 		return nil
@@ -98,18 +148,191 @@ var describeBrickCmd = &cobra.Command{
 	},
 }
 
+var whichBrickCmd = &cobra.Command{
+	Use:           "which [brickId]",
+	Short:         "Shows the remote that a brick is taken from and all copies of the brick that are shadowed by it",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("brick id argument is missing")
+		}
+		origins, err := brickApi.Which(args[0])
+		if err != nil {
+			return err
+		}
+		pinned := ""
+		if origins[0].Pinned {
+			pinned = ", pinned"
+		}
+		fmt.Printf("%s %s from remote %s (%s%s)\n", origins[0].Brick.Id, origins[0].Brick.Version, origins[0].Remote.Name, origins[0].Brick.BasePath, pinned)
+		if len(origins) > 1 {
+			fmt.Println("shadowed copies:")
+			for _, o := range origins[1:] {
+				fmt.Printf("  %s %s in remote %s (%s)\n", o.Brick.Id, o.Brick.Version, o.Remote.Name, o.Brick.BasePath)
+			}
+		}
+		return nil
+	},
+}
+
+var schemaBrickCmd = &cobra.Command{
+	Use:           "schema",
+	Short:         "Prints the JSON Schema of a brick's manifest.yaml for editor validation",
+	Example:       "  sapper brick schema > manifest.schema.json",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return brickApi.Schema(os.Stdout)
+	},
+}
+
+var packBrickCmd = &cobra.Command{
+	Use:           "pack [brick folder]",
+	Short:         "Validates a brick and packs it into a versioned archive",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("brick folder argument is missing")
+		}
+		output, _ := cmd.Flags().GetString("output")
+		pkg, err := brickApi.Pack(args[0], output)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("packed %s %s into %s\n", pkg.Id, pkg.Version, pkg.Path)
+		fmt.Printf("digest: %s\n", pkg.Digest)
+		return nil
+	},
+}
+
+var publishBrickCmd = &cobra.Command{
+	Use:           "publish [package] [remote_name]",
+	Short:         "Publishes a packed brick to a remote. Existing versions are never overwritten.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("package and/or remote_name arguments are missing")
+		}
+		return brickApi.Publish(args[0], args[1])
+	},
+}
+
+var extractBrickCmd = &cobra.Command{
+	Use:           "extract",
+	Short:         "Creates a new brick from the changes made to a service since a git revision",
+	Example:       "  sapper brick extract --from my-service --since HEAD~1 --into local/repo-redis",
+	Annotations:   map[string]string{serviceFolderAnnotation: "from"},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		since, _ := cmd.Flags().GetString("since")
+		into, _ := cmd.Flags().GetString("into")
+		if since == "" {
+			return errors.New("--since flag is missing")
+		}
+		if into == "" {
+			return errors.New("--into flag is missing")
+		}
+		brick, err := brickApi.Extract(from, since, into, os.Stdout)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("extracted brick %s into %s\n", brick.Id, brick.BasePath)
+		for _, p := range brick.Parameters {
+			fmt.Printf("  detected parameter %s (default: %s)\n", p.Name, p.Default)
+		}
+		return nil
+	},
+}
+
+var testBrickCmd = &cobra.Command{
+	Use:   "test [brickIds...]",
+	Short: "Builds and tests bricks on their own and optionally in pairs and reports a compatibility matrix",
+	Long: `Builds and tests bricks on their own and optionally in pairs and reports a compatibility matrix.
+All extension bricks are tested if no brick ids are provided.`,
+	Example:       "  sapper brick test handler-http repo-postgres --pairs\n  sapper brick test --pairs --format junit --output brick-test.xml",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		template, _ := cmd.Flags().GetString("with-template")
+		pairs, _ := cmd.Flags().GetBool("pairs")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		writeReport := map[string]func(ports.BrickTestReport, io.Writer) error{
+			"text":  utils.WriteBrickTestMatrix,
+			"json":  utils.WriteBrickTestJSON,
+			"junit": utils.WriteBrickTestJUnit,
+		}[format]
+		if writeReport == nil {
+			return fmt.Errorf("invalid format %s. Must be one of text, json, or junit", format)
+		}
+
+		report, err := brickApi.Test(args, template, pairs, os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := writeReport(report, w); err != nil {
+			return err
+		}
+
+		failures := 0
+		for _, r := range report.Results {
+			if r.Status != ports.BrickTestPassed {
+				failures++
+			}
+		}
+		if failures > 0 {
+			return fmt.Errorf("%v of %v brick combinations failed", failures, len(report.Results))
+		}
+		return nil
+	},
+}
+
 func init() {
 	brickCmd.AddCommand(addBrickCmd)
 	brickCmd.AddCommand(upgradeBrickCmd)
 	brickCmd.AddCommand(listBrickCmd)
 	brickCmd.AddCommand(searchBrickCmd)
 	brickCmd.AddCommand(describeBrickCmd)
+	brickCmd.AddCommand(whichBrickCmd)
+	brickCmd.AddCommand(packBrickCmd)
+	brickCmd.AddCommand(publishBrickCmd)
+	brickCmd.AddCommand(schemaBrickCmd)
+	brickCmd.AddCommand(extractBrickCmd)
+	brickCmd.AddCommand(testBrickCmd)
 
 	rootCmd.AddCommand(brickCmd)
 
 	addBrickCmd.PersistentFlags().StringP("service", "s", ".", "Path to the service that the brick shall be added to.")
 	parameterResolver.RegisterSapperParameterResolver(addBrickCmd.PersistentFlags())
 
+	listBrickCmd.Flags().StringP("kind", "k", "extension", "Kind of the bricks to be listed (template, extension, helper, or all).")
+	searchBrickCmd.Flags().StringP("kind", "k", "extension", "Kind of the bricks to be searched (template, extension, helper, or all).")
+	listBrickCmd.Flags().Bool("all-versions", false, "Lists all versions of each brick instead of just the highest version.")
+	packBrickCmd.Flags().StringP("output", "o", ".", "Folder that the brick package is written to.")
+	testBrickCmd.Flags().String("with-template", "base-hexagonal-skeleton", "The id of the service template that the bricks are tested with.")
+	testBrickCmd.Flags().Bool("pairs", false, "Additionally tests all pairwise combinations of the bricks.")
+	testBrickCmd.Flags().String("format", "text", "Format of the compatibility matrix (text, json, or junit).")
+	testBrickCmd.Flags().StringP("output", "o", "", "File that the compatibility matrix is written to instead of standard output.")
+	extractBrickCmd.Flags().String("from", ".", "Path to the service that the changes are extracted from.")
+	extractBrickCmd.Flags().String("since", "", "Git revision of the service that the changes are compared to.")
+	extractBrickCmd.Flags().String("into", "", "Target of the new brick in the form <filesystem remote>/<brick id>.")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
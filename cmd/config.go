@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration",
+}
+
+// parameterKey returns the configuration key of a parameter. set-param and unset-param are shorthands for set and unset of this key.
+func parameterKey(name string) string {
+	return "Parameters." + name
+}
+
+var setParamConfigCmd = &cobra.Command{
+	Use:           "set-param [PARAM_NAME=value...]",
+	Short:         "Set default values of brick parameters that are used if they are not passed with -p. Lists the parameters if no arguments are given.",
+	Example:       "  sapper config set-param CONTAINER_REGISTRY=registry.example.com\n  sapper config set-param",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			parameters := configApi.Parameters()
+			names := []string{}
+			for name := range parameters {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("%s=%s\n", name, parameters[name])
+			}
+			return nil
+		}
+		for _, arg := range args {
+			name, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("parameter %s must be of the form 'PARAMETER_NAME=value'", arg)
+			}
+			if err := configApi.Set(parameterKey(name), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var unsetParamConfigCmd = &cobra.Command{
+	Use:           "unset-param PARAM_NAME",
+	Short:         "Remove the default value of a brick parameter",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("PARAM_NAME argument is missing")
+		}
+		return configApi.Unset(parameterKey(args[0]))
+	},
+}
+
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+var listConfigCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List the effective configuration and where each value comes from (user, project, env, flag, or default)",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		values := configApi.List()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return printJSON(values)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, v := range values {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, v.Source)
+		}
+		return w.Flush()
+	},
+}
+
+var getConfigCmd = &cobra.Command{
+	Use:           "get key",
+	Short:         "Print a configuration value",
+	Example:       "  sapper config get RemoteUpdateTTL\n  sapper config get Parameters.CONTAINER_REGISTRY --json",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("key argument is missing")
+		}
+		value, err := configApi.Get(args[0])
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return printJSON(value)
+		}
+		fmt.Println(value.Value)
+		return nil
+	},
+}
+
+var setConfigCmd = &cobra.Command{
+	Use:           "set key value",
+	Short:         "Set a value of the active profile in the user's configuration",
+	Example:       "  sapper config set RemoteUpdateTTL 24h\n  sapper config set Parameters.CONTAINER_REGISTRY registry.example.com",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("key and/or value arguments are missing")
+		}
+		return configApi.Set(args[0], args[1])
+	},
+}
+
+var unsetConfigCmd = &cobra.Command{
+	Use:           "unset key",
+	Short:         "Remove a value of the active profile from the user's configuration",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("key argument is missing")
+		}
+		return configApi.Unset(args[0])
+	},
+}
+
+var pathConfigCmd = &cobra.Command{
+	Use:           "path",
+	Short:         "Print the path of the user's configuration file",
+	Annotations:   map[string]string{invalidConfigAnnotation: ""},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(configApi.Path())
+	},
+}
+
+// editor returns the editor that the user has chosen by $VISUAL or $EDITOR
+func editor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := os.Getenv(env); e != "" {
+			return e
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+var editConfigCmd = &cobra.Command{
+	Use:           "edit",
+	Short:         "Open the user's configuration file in $VISUAL or $EDITOR and validate it afterwards",
+	Annotations:   map[string]string{invalidConfigAnnotation: ""},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		editorArgs := strings.Fields(editor())
+		c := exec.Command(editorArgs[0], append(editorArgs[1:], configApi.Path())...)
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return err
+		}
+		if configLoader == nil {
+			return nil
+		}
+		if err := configLoader(cfgFile, profile, serviceFolder(cmd, args)); err != nil {
+			return fmt.Errorf("the configuration is invalid: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(listConfigCmd)
+	configCmd.AddCommand(getConfigCmd)
+	configCmd.AddCommand(setConfigCmd)
+	configCmd.AddCommand(unsetConfigCmd)
+	configCmd.AddCommand(editConfigCmd)
+	configCmd.AddCommand(pathConfigCmd)
+	configCmd.AddCommand(setParamConfigCmd)
+	configCmd.AddCommand(unsetParamConfigCmd)
+
+	listConfigCmd.Flags().Bool("json", false, "Print the configuration as JSON")
+	getConfigCmd.Flags().Bool("json", false, "Print the value along with its source as JSON")
+
+	rootCmd.AddCommand(configCmd)
+}
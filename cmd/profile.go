@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage profiles, i.e. named sets of remotes and defaults",
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:           "use profile_name",
+	Short:         "Activate a profile for all subsequent commands. Use --profile to select a profile for a single command.",
+	Example:       "  sapper profile use internal\n  sapper profile use default",
+	Annotations:   map[string]string{invalidConfigAnnotation: ""},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("profile_name argument is missing")
+		}
+		return profileApi.Use(args[0])
+	},
+}
+
+var addProfileCmd = &cobra.Command{
+	Use:           "add profile_name",
+	Short:         "Add a new profile with the default remote",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("profile_name argument is missing")
+		}
+		return profileApi.Add(args[0])
+	},
+}
+
+var removeProfileCmd = &cobra.Command{
+	Use:           "remove profile_name",
+	Short:         "Remove a profile",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("profile_name argument is missing")
+		}
+		return profileApi.Remove(args[0])
+	},
+}
+
+var listProfileCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List all profiles. The profile in use is marked with *.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		profiles, active := profileApi.List()
+		for _, p := range profiles {
+			marker := " "
+			if p == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, p)
+		}
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(useProfileCmd)
+	profileCmd.AddCommand(addProfileCmd)
+	profileCmd.AddCommand(removeProfileCmd)
+	profileCmd.AddCommand(listProfileCmd)
+
+	rootCmd.AddCommand(profileCmd)
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -39,16 +41,64 @@ var removeRemoteCmd = &cobra.Command{
 	},
 }
 
+var renameRemoteCmd = &cobra.Command{
+	Use:           "rename remote_name new_remote_name",
+	Short:         "Rename a remote",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("remote_name and/or new_remote_name arguments are missing")
+		}
+		return remoteApi.Rename(args[0], args[1])
+	},
+}
+
+var moveRemoteCmd = &cobra.Command{
+	Use:           "move remote_name position",
+	Short:         "Move a remote to another position. Remotes with a lower position take precedence.",
+	Example:       "  sapper remote move my-bricks 0",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("remote_name and/or position arguments are missing")
+		}
+		position, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid position %s", args[1])
+		}
+		return remoteApi.Move(args[0], position)
+	},
+}
+
+var setUrlRemoteCmd = &cobra.Command{
+	Use:           "set-url remote_name remote_url",
+	Short:         "Change the git url or file system path of a remote",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return errors.New("remote_name and/or remote_src arguments are missing")
+		}
+		return remoteApi.SetUrl(args[0], args[1])
+	},
+}
+
 var updateRemoteCmd = &cobra.Command{
 	Use:           "update git_remote_name",
 	Short:         "Pulls latest version for git remotes. No effect on file based remotes.",
+	Example:       "  sapper remote update sapper-bricks\n  sapper remote update --all",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			return remoteApi.UpdateAll(os.Stdout)
+		}
 		if len(args) < 1 {
 			return errors.New("remote_name argument is missing")
 		}
-		return remoteApi.Update(args[0])
+		return remoteApi.Update(args[0], os.Stdout)
 	},
 }
 
@@ -65,6 +115,52 @@ var upgradeRemoteCmd = &cobra.Command{
 	},
 }
 
+var verifyRemoteCmd = &cobra.Command{
+	Use:           "verify remote_name",
+	Short:         "Verifies all bricks and templates in a remote (e.g. manifests, dependencies, parameters, and sections).",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("remote_name argument is missing")
+		}
+		return remoteApi.Verify(args[0], os.Stdout)
+	},
+}
+
+var pinRemoteCmd = &cobra.Command{
+	Use:           "pin [brick remote_name]",
+	Short:         "Take a brick only from a given remote. The brick may be a glob pattern. Lists all pins if no arguments are given.",
+	Example:       "  sapper remote pin cpp-rest-client internal\n  sapper remote pin 'cpp-*' internal\n  sapper remote pin",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			for _, p := range remoteApi.Pins() {
+				fmt.Printf("%s: %s\n", p.Brick, p.Remote)
+			}
+			return nil
+		}
+		if len(args) < 2 {
+			return errors.New("remote_name argument is missing")
+		}
+		return remoteApi.Pin(args[0], args[1])
+	},
+}
+
+var unpinRemoteCmd = &cobra.Command{
+	Use:           "unpin brick",
+	Short:         "Remove the pin of a brick",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("brick argument is missing")
+		}
+		return remoteApi.Unpin(args[0])
+	},
+}
+
 var listRemoteCmd = &cobra.Command{
 	Use:           "list",
 	Short:         "List current remotes",
@@ -80,11 +176,18 @@ var listRemoteCmd = &cobra.Command{
 func init() {
 	remoteCmd.AddCommand(addRemoteCmd)
 	remoteCmd.AddCommand(removeRemoteCmd)
+	remoteCmd.AddCommand(renameRemoteCmd)
+	remoteCmd.AddCommand(moveRemoteCmd)
+	remoteCmd.AddCommand(setUrlRemoteCmd)
 	remoteCmd.AddCommand(updateRemoteCmd)
 	remoteCmd.AddCommand(upgradeRemoteCmd)
+	remoteCmd.AddCommand(verifyRemoteCmd)
+	remoteCmd.AddCommand(pinRemoteCmd)
+	remoteCmd.AddCommand(unpinRemoteCmd)
 	remoteCmd.AddCommand(listRemoteCmd)
 
 	rootCmd.AddCommand(remoteCmd)
 
 	addRemoteCmd.Flags().IntP("insert", "i", -1, "Insert the remote at a given position")
+	updateRemoteCmd.Flags().BoolP("all", "a", false, "Pulls the latest version of all git remotes concurrently")
 }
@@ -6,21 +6,31 @@ import (
 
 	"github.com/seboste/sapper/ports"
 	"github.com/spf13/cobra"
-	//homedir "github.com/mitchellh/go-homedir"
-	//"github.com/spf13/viper"
 )
 
 var cfgFile string
+var profile string
+var configLoader func(configFile string, profile string, projectDir string) error
 
 var brickApi ports.BrickApi = nil
 var serviceApi ports.ServiceApi = nil
 var remoteApi ports.RemoteApi = nil
+var profileApi ports.ProfileApi = nil
+var configApi ports.ConfigApi = nil
 var version string
 
-func SetApis(b ports.BrickApi, s ports.ServiceApi, r ports.RemoteApi) {
+func SetApis(b ports.BrickApi, s ports.ServiceApi, r ports.RemoteApi, p ports.ProfileApi, c ports.ConfigApi) {
 	brickApi = b
 	serviceApi = s
 	remoteApi = r
+	profileApi = p
+	configApi = c
+}
+
+// SetConfigLoader sets the function that loads the configuration from the config file (empty for the default) with the given
+// profile (empty for the active profile) and the project configuration found in or above projectDir. It is called before each command.
+func SetConfigLoader(loader func(configFile string, profile string, projectDir string) error) {
+	configLoader = loader
 }
 
 func SetVersion(v string) {
@@ -34,6 +44,7 @@ var rootCmd = &cobra.Command{
 	Long: `
 Sapper is CLI tool for the rapid development of C++ microservices.
 `,
+	PersistentPreRunE: initConfig,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -46,43 +57,50 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sapper.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $SAPPER_HOME/config.yaml with SAPPER_HOME defaulting to $HOME/.sapper)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "profile to use instead of the active profile (see sapper profile)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	/*
-		if cfgFile != "" {
-			// Use config file from the flag.
-			viper.SetConfigFile(cfgFile)
-		} else {
-			// Find home directory.
-			home, err := homedir.Dir()
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
-			}
-
-			// Search config in home directory with name ".sapper" (without extension).
-			viper.AddConfigPath(home)
-			viper.SetConfigName(".sapper")
-		}
-
-		viper.AutomaticEnv() // read in environment variables that match
-
-		// If a config file is found, read it in.
-		if err := viper.ReadInConfig(); err == nil {
-			fmt.Println("Using config file:", viper.ConfigFileUsed())
-		}
-	*/
+// serviceFolderAnnotation marks commands that operate on a service. Its value is the name of the flag that holds the
+// service folder or empty if the service folder is the first argument.
+const serviceFolderAnnotation = "service-folder"
+
+// serviceFolder returns the folder that the project configuration is searched from
+func serviceFolder(cmd *cobra.Command, args []string) string {
+	flag, ok := cmd.Annotations[serviceFolderAnnotation]
+	if !ok {
+		return "."
+	}
+	if flag != "" {
+		folder, _ := cmd.Flags().GetString(flag)
+		return folder
+	}
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "."
+}
+
+// invalidConfigAnnotation marks commands that can be run even if the configuration is invalid (e.g. to fix it)
+const invalidConfigAnnotation = "invalid-config"
+
+// initConfig loads the configuration once the flags have been parsed
+func initConfig(cmd *cobra.Command, args []string) error {
+	if configLoader == nil {
+		return nil
+	}
+	err := configLoader(cfgFile, profile, serviceFolder(cmd, args))
+	if _, ok := cmd.Annotations[invalidConfigAnnotation]; ok && err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return nil
+	}
+	return err
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	parameterResolver "github.com/seboste/sapper/adapters/parameter-resolver"
@@ -22,6 +23,7 @@ var stopAfter *time.Duration
 var addServiceCmd = &cobra.Command{
 	Use:           "add [folder]",
 	Short:         "Adds a new C++ microservice",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -32,11 +34,14 @@ var addServiceCmd = &cobra.Command{
 		path, name := filepath.Split(args[0])
 		template, _ := cmd.Flags().GetString("template")
 
-		r, err := parameterResolver.MakeSapperParameterResolver(cmd.Flags(), name)
+		r, err := parameterResolver.MakeSapperParameterResolver(cmd.Flags(), name, configApi.Parameters())
 		if err != nil {
 			return err
 		}
 
+		if err := remoteApi.UpdateStale(os.Stdout); err != nil {
+			return err
+		}
 		_, err = serviceApi.Add(template, path, r)
 		return err
 	},
@@ -45,6 +50,7 @@ var addServiceCmd = &cobra.Command{
 var describeServiceCmd = &cobra.Command{
 	Use:           "describe [service folder]",
 	Short:         "Prints information about a service",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -55,9 +61,58 @@ var describeServiceCmd = &cobra.Command{
 	},
 }
 
+var verifyBricksServiceCmd = &cobra.Command{
+	Use:           "verify-bricks [service folder]",
+	Short:         "Checks if the content of the service's bricks has changed in the remotes without a version bump",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("service folder argument is missing")
+		}
+		return serviceApi.VerifyBricks(args[0], os.Stdout)
+	},
+}
+
+var vendorServiceCmd = &cobra.Command{
+	Use:           "vendor [service folder]",
+	Short:         "Copies the bricks used by the service into .sapper/bricks so that they take precedence over the remotes",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("service folder argument is missing")
+		}
+		return serviceApi.Vendor(args[0], os.Stdout)
+	},
+}
+
+var setParamServiceCmd = &cobra.Command{
+	Use:           "set-param PARAM_NAME=value",
+	Short:         "Changes a parameter of the service and updates the files of the bricks that use it",
+	Example:       "  sapper service set-param PORT=8081 --service my-service",
+	Annotations:   map[string]string{serviceFolderAnnotation: "service"},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("PARAM_NAME=value argument is missing")
+		}
+		name, value, ok := strings.Cut(args[0], "=")
+		if !ok {
+			return fmt.Errorf("parameter %s must be of the form 'PARAMETER_NAME=value'", args[0])
+		}
+		service, _ := cmd.Flags().GetString("service")
+		return serviceApi.SetParameter(service, name, value, os.Stdout)
+	},
+}
+
 var upgradeServiceCmd = &cobra.Command{
 	Use:           "upgrade [service folder]",
 	Short:         "upgrades the dependencies of the service",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -69,6 +124,7 @@ var upgradeServiceCmd = &cobra.Command{
 var buildServiceCmd = &cobra.Command{
 	Use:           "build [service folder]",
 	Short:         "Builds the service",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -92,6 +148,7 @@ var buildServiceCmd = &cobra.Command{
 var testServiceCmd = &cobra.Command{
 	Use:           "test [service folder]",
 	Short:         "Tests the service",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -113,6 +170,7 @@ var testServiceCmd = &cobra.Command{
 var deployServiceCmd = &cobra.Command{
 	Use:           "deploy [service folder]",
 	Short:         "Deploy the service",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -134,6 +192,7 @@ var deployServiceCmd = &cobra.Command{
 var runServiceCmd = &cobra.Command{
 	Use:           "run [service folder]",
 	Short:         "Run the service",
+	Annotations:   map[string]string{serviceFolderAnnotation: ""},
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -155,6 +214,9 @@ var runServiceCmd = &cobra.Command{
 func init() {
 	serviceCmd.AddCommand(addServiceCmd)
 	serviceCmd.AddCommand(describeServiceCmd)
+	serviceCmd.AddCommand(verifyBricksServiceCmd)
+	serviceCmd.AddCommand(vendorServiceCmd)
+	serviceCmd.AddCommand(setParamServiceCmd)
 	serviceCmd.AddCommand(upgradeServiceCmd)
 	serviceCmd.AddCommand(buildServiceCmd)
 	serviceCmd.AddCommand(testServiceCmd)
@@ -166,6 +228,8 @@ func init() {
 	addServiceCmd.PersistentFlags().StringP("template", "t", "base-hexagonal-skeleton", "The id of a service template.")
 	parameterResolver.RegisterSapperParameterResolver(addServiceCmd.PersistentFlags())
 
+	setParamServiceCmd.PersistentFlags().StringP("service", "s", ".", "Path to the service whose parameter shall be changed.")
+
 	keepMajorVersion = upgradeServiceCmd.PersistentFlags().Bool("keep-major", false, "Upgrades are only conducted within the same major version of a dependency's semantic version")
 	stopAfter = runServiceCmd.PersistentFlags().Duration("stop-after", 0, "Stops the service after a specified time has elapsed. Can be used for e.g. smoke tests.")
 }
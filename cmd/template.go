@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/seboste/sapper/ports"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Discover service templates",
+}
+
+var listTemplateCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "Displays the templates that can be used by 'sapper service add --template'",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		allVersions, _ := cmd.Flags().GetBool("all-versions")
+		templates := brickApi.List([]ports.BrickKind{ports.Template}, allVersions)
+		fmt.Printf("found a total of %d templates\n", len(templates))
+		for _, t := range templates {
+			Print(t)
+		}
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(listTemplateCmd)
+
+	rootCmd.AddCommand(templateCmd)
+
+	listTemplateCmd.Flags().Bool("all-versions", false, "Lists all versions of each template instead of just the highest version.")
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/seboste/sapper/ports"
+	"github.com/seboste/sapper/utils"
 	pr "github.com/seboste/sapper/utils/parameter-resolver"
 )
 
@@ -19,6 +20,10 @@ type BrickApi struct {
 	PackageDependencyReader ports.BrickPackageDependencyReader
 	PackageDependencyWriter ports.BrickPackageDependencyWriter
 	DependencyInfo          ports.DependencyInfo
+	BrickPackager           ports.BrickPackager
+	ManifestSchema          ports.BrickManifestSchema
+	ManifestWriter          ports.BrickManifestWriter
+	VersionControl          ports.VersionControl
 	ServiceApi              ServiceApi
 }
 
@@ -38,7 +43,7 @@ func removeBricks(bricks []ports.Brick, brickIdsToRemove []ports.BrickDependency
 }
 
 func (b BrickApi) Add(servicePath string, brickId string, parameterResolver ports.ParameterResolver) error {
-	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(b.Configuration.Remotes(), b.Configuration.DefaultRemotesDir())
+	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(serviceRemotes(servicePath, b.Configuration.Remotes()), b.Configuration.DefaultRemotesDir())
 	if err != nil {
 		return err
 	}
@@ -53,12 +58,21 @@ func (b BrickApi) Add(servicePath string, brickId string, parameterResolver port
 		return err
 	}
 
+	for _, d := range service.BrickIds {
+		if changed, message := verifyBrickDependency(d, db); changed {
+			fmt.Printf("warning: brick %s %s: %s\n", d.Id, d.Version, message)
+		}
+	}
+
 	bricks = removeBricks(bricks, service.BrickIds) //remove all bricks that are already there
 
 	if len(bricks) == 0 {
 		return fmt.Errorf("brick %s has already been added.", brickId)
 	}
 
+	if err := validateParameters(bricks, service.Parameters, parameterResolver); err != nil {
+		return err
+	}
 	parameters, err := ResolveParameterSlice(bricks, pr.MakeCompoundParameterResolver([]ports.ParameterResolver{
 		pr.MakeMapBasedParameterResolver(service.Parameters), //first check if parameters have already been defined in the service...
 		parameterResolver, //...if not, ask the external parameter resolver
@@ -68,7 +82,7 @@ func (b BrickApi) Add(servicePath string, brickId string, parameterResolver port
 	}
 
 	for _, brick := range bricks {
-		if err := AddSingleBrick(&service, brick, parameters); err != nil {
+		if err := addBrick(&service, brick, parameters); err != nil {
 			return err
 		}
 	}
@@ -194,23 +208,30 @@ func (b BrickApi) Upgrade(brickId string) error {
 	return b.UpgradeInDB(brickId, db)
 }
 
-func (b BrickApi) List() []ports.Brick {
+func (b BrickApi) List(kinds []ports.BrickKind, allVersions bool) []ports.Brick {
 	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(b.Configuration.Remotes(), b.Configuration.DefaultRemotesDir())
 	if err != nil {
 		return nil
 	}
 
-	return db.Bricks(ports.Extension)
-}
+	bricks := []ports.Brick{}
+	for _, k := range kinds {
+		bricks = append(bricks, db.Bricks(k)...)
+	}
+	if !allVersions {
+		return bricks
+	}
 
-func (b BrickApi) Search(term string) []ports.Brick {
-	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(b.Configuration.Remotes(), b.Configuration.DefaultRemotesDir())
-	if err != nil {
-		return nil
+	versions := []ports.Brick{}
+	for _, brick := range bricks {
+		versions = append(versions, db.BrickVersions(brick.Id)...)
 	}
+	return versions
+}
 
+func (b BrickApi) Search(term string, kinds []ports.BrickKind) []ports.Brick {
 	filteredBricks := []ports.Brick{}
-	for _, brick := range db.Bricks(ports.Extension) {
+	for _, brick := range b.List(kinds, false) {
 		if strings.Contains(brick.Id, term) || strings.Contains(brick.Description, term) {
 			filteredBricks = append(filteredBricks, brick)
 		}
@@ -218,13 +239,79 @@ func (b BrickApi) Search(term string) []ports.Brick {
 	return filteredBricks
 }
 
+// Origins returns the copies of all bricks found in the remotes. The copies are ordered by the remotes' priority,
+// i.e. the first copy is the one being used and all others are shadowed.
+func (b BrickApi) Origins() (map[string][]ports.BrickOrigin, error) {
+	remotes := b.Configuration.Remotes()
+	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(remotes, b.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return nil, err
+	}
+	adb, ok := db.(ports.AggregatedBrickDB)
+	if !ok || len(adb.RemoteBrickDBs()) != len(remotes) {
+		return nil, fmt.Errorf("unable to determine the origins of the bricks")
+	}
+
+	origins := map[string][]ports.BrickOrigin{}
+	for i, remoteDB := range adb.RemoteBrickDBs() {
+		for _, brick := range allBricks(remoteDB) {
+			origins[brick.Id] = append(origins[brick.Id], ports.BrickOrigin{Remote: remotes[i], Brick: brick})
+		}
+	}
+
+	for id, o := range origins {
+		origins[id] = applyPin(o, b.Configuration.Pins(), id)
+	}
+	return origins, nil
+}
+
+// applyPin moves the copy of the remote that the brick is pinned to to the front
+func applyPin(origins []ports.BrickOrigin, pins []ports.BrickPin, brickId string) []ports.BrickOrigin {
+	pin, ok := ports.MatchingPin(pins, brickId)
+	if !ok {
+		return origins
+	}
+	for i, o := range origins {
+		if o.Remote.Name == pin.Remote {
+			o.Pinned = true
+			pinned := []ports.BrickOrigin{o}
+			pinned = append(pinned, origins[:i]...)
+			return append(pinned, origins[i+1:]...)
+		}
+	}
+	return origins
+}
+
+func (b BrickApi) Which(brickId string) ([]ports.BrickOrigin, error) {
+	origins, err := b.Origins()
+	if err != nil {
+		return nil, err
+	}
+	if len(origins[brickId]) == 0 {
+		return nil, fmt.Errorf("brick %s: %w", brickId, ports.BrickNotFound)
+	}
+	if pin, ok := ports.MatchingPin(b.Configuration.Pins(), brickId); ok && !origins[brickId][0].Pinned {
+		return nil, fmt.Errorf("brick %s is pinned to remote %s which does not provide it: %w", brickId, pin.Remote, ports.BrickNotFound)
+	}
+	return origins[brickId], nil
+}
+
+func (b BrickApi) Schema(writer io.Writer) error {
+	schema, err := b.ManifestSchema.JSONSchema()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(schema))
+	return err
+}
+
 func (b BrickApi) Describe(brickId string, writer io.Writer) error {
 	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(b.Configuration.Remotes(), b.Configuration.DefaultRemotesDir())
 	if err != nil {
 		return err
 	}
 
-	brick, err := db.Brick(brickId)
+	brick, err := findBrick(db, brickId)
 	if err != nil {
 		return err
 	}
@@ -247,5 +334,248 @@ func (b BrickApi) Describe(brickId string, writer io.Writer) error {
 	return nil
 }
 
+func validateBrick(brick ports.Brick) error {
+	if brick.Id == "" {
+		return fmt.Errorf("brick in %s has no id", brick.BasePath)
+	}
+	if _, err := ports.ParseSemanticVersion(brick.Version); err != nil {
+		return fmt.Errorf("brick %s has an invalid version: %v", brick.Id, err)
+	}
+	return nil
+}
+
+func checkVersionBump(published ports.Brick, candidate ports.Brick) error {
+	if published.Version == candidate.Version {
+		return fmt.Errorf("version %s of brick %s has already been published", candidate.Version, candidate.Id)
+	}
+	publishedVersion, err := ports.ParseSemanticVersion(published.Version)
+	if err != nil {
+		return err
+	}
+	candidateVersion, err := ports.ParseSemanticVersion(candidate.Version)
+	if err != nil {
+		return err
+	}
+	if !ports.Less(publishedVersion, candidateVersion) {
+		return fmt.Errorf("version %s of brick %s must be greater than the published version %s", candidate.Version, candidate.Id, published.Version)
+	}
+	return nil
+}
+
+// findPublishedCopy returns the highest published version of the brick within the candidate's major version.
+// Thereby, older major versions can still be maintained side by side.
+func findPublishedCopy(db ports.BrickDB, candidate ports.Brick) (ports.Brick, error) {
+	v, err := ports.ParseSemanticVersion(candidate.Version)
+	if err != nil {
+		return ports.Brick{}, err
+	}
+	constraint, err := ports.ParseVersionConstraint(fmt.Sprintf(">=%d.0.0 <%d.0.0", v.Major, v.Major+1))
+	if err != nil {
+		return ports.Brick{}, err
+	}
+	return db.BrickMatching(candidate.Id, constraint)
+}
+
+func brickDigest(brick ports.Brick) (string, error) {
+	return utils.DigestFiles(brick.BasePath, append([]string{"manifest.yaml"}, brick.Files...))
+}
+
+func allBricks(db ports.BrickDB) []ports.Brick {
+	bricks := []ports.Brick{}
+	for _, k := range ports.BrickKinds {
+		bricks = append(bricks, db.Bricks(k)...)
+	}
+	return bricks
+}
+
+// loadBrick reads the single brick located in path
+func (b BrickApi) loadBrick(path string) (ports.Brick, error) {
+	db, err := b.BrickDBFactory.MakeBrickDB(ports.Remote{Name: path, Kind: ports.FilesystemRemote, Src: path}, "")
+	if err != nil {
+		return ports.Brick{}, err
+	}
+	if invalidManifests := db.InvalidManifests(); len(invalidManifests) > 0 {
+		return ports.Brick{}, fmt.Errorf("invalid manifest %s: %v", invalidManifests[0].Path, invalidManifests[0].Err)
+	}
+	bricks := allBricks(db)
+	if len(bricks) != 1 {
+		return ports.Brick{}, fmt.Errorf("expected exactly one brick in %s, found %d", path, len(bricks))
+	}
+	return bricks[0], nil
+}
+
+// checkBrick runs the checks of 'sapper remote verify' on the single brick located in path. Dependencies are resolved with the brick
+// itself and the configured remotes. Warnings are printed, errors are returned.
+func (b BrickApi) checkBrick(brick ports.Brick, path string) error {
+	remotes := append([]ports.Remote{{Name: path, Kind: ports.FilesystemRemote, Src: path}}, b.Configuration.Remotes()...)
+	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(remotes, b.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
+	}
+	issues := verifyBrick(brick, db)
+	for _, w := range issues.warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	if len(issues.errors) > 0 {
+		return fmt.Errorf("brick %s %s is invalid:\n  %s", brick.Id, brick.Version, strings.Join(issues.errors, "\n  "))
+	}
+	return nil
+}
+
+// isWithin tells whether path is dir or one of its subfolders
+func isWithin(path string, dir string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false, nil //e.g. on another drive
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
+
+func (b BrickApi) Pack(brickPath string, outputDir string) (ports.BrickPackage, error) {
+	//packages written into the brick would become part of later packages
+	if within, err := isWithin(outputDir, brickPath); err != nil {
+		return ports.BrickPackage{}, err
+	} else if within {
+		return ports.BrickPackage{}, fmt.Errorf("output folder %s is inside the brick folder %s. Choose a folder outside of the brick with --output", outputDir, brickPath)
+	}
+
+	brick, err := b.loadBrick(brickPath)
+	if err != nil {
+		return ports.BrickPackage{}, err
+	}
+	if err := b.checkBrick(brick, brickPath); err != nil {
+		return ports.BrickPackage{}, err
+	}
+
+	digest, err := brickDigest(brick)
+	if err != nil {
+		return ports.BrickPackage{}, err
+	}
+
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return ports.BrickPackage{}, err
+	}
+
+	pkg := ports.BrickPackage{
+		Id:      brick.Id,
+		Version: brick.Version,
+		Digest:  digest,
+		Path:    filepath.Join(outputDir, fmt.Sprintf("%s-%s.tar.gz", brick.Id, brick.Version)),
+	}
+	if err := b.BrickPackager.Pack(brick, pkg.Path); err != nil {
+		return pkg, err
+	}
+	if err := ioutil.WriteFile(pkg.Path+".sha256", []byte(fmt.Sprintln(pkg.Digest)), 0644); err != nil {
+		return pkg, err
+	}
+	return pkg, nil
+}
+
+// undeclaredFiles returns the files in the base path of a brick that are neither its manifest nor one of its files
+func undeclaredFiles(brick ports.Brick) ([]string, error) {
+	declared := map[string]bool{"manifest.yaml": true}
+	for _, f := range brick.Files {
+		declared[filepath.ToSlash(filepath.Clean(f))] = true
+	}
+	files := []string{}
+	err := filepath.Walk(brick.BasePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(brick.BasePath, path)
+		if err != nil {
+			return err
+		}
+		if !declared[filepath.ToSlash(rel)] {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (b BrickApi) Publish(packagePath string, remoteName string) error {
+	_, remote, ok := findRemote(b.Configuration.Remotes(), remoteName)
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", remoteName)
+	}
+
+	//1. unpack and validate the brick
+	tempDir, err := ioutil.TempDir("", "sapper_publish_*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := b.BrickPackager.Unpack(packagePath, tempDir); err != nil {
+		return err
+	}
+	brick, err := b.loadBrick(tempDir)
+	if err != nil {
+		return err
+	}
+	if err := b.checkBrick(brick, tempDir); err != nil {
+		return err
+	}
+	if files, err := undeclaredFiles(brick); err != nil {
+		return err
+	} else if len(files) > 0 {
+		return fmt.Errorf("package %s contains files that are not declared in the manifest: %s", packagePath, strings.Join(files, ", "))
+	}
+
+	//2. make sure that the content matches the digest (if available)
+	if expectedDigest, err := ioutil.ReadFile(packagePath + ".sha256"); err == nil {
+		digest, err := brickDigest(brick)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(string(expectedDigest)) != digest {
+			return fmt.Errorf("digest of %s does not match the content of the package", packagePath)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	//3. make sure that the version has been bumped
+	db, err := b.BrickDBFactory.MakeBrickDB(remote, b.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
+	}
+	for _, published := range db.BrickVersions(brick.Id) {
+		if published.Version == brick.Version {
+			return fmt.Errorf("version %s of brick %s has already been published", brick.Version, brick.Id)
+		}
+	}
+	if published, err := findPublishedCopy(db, brick); err == nil {
+		if err := checkVersionBump(published, brick); err != nil {
+			return err
+		}
+	}
+
+	targetDir := filepath.Join(remoteDir(remote, b.Configuration.DefaultRemotesDir()), fmt.Sprintf("%s-%s", brick.Id, brick.Version))
+	if _, err := os.Stat(targetDir); err == nil {
+		return fmt.Errorf("unable to publish %s %s: %s does already exist", brick.Id, brick.Version, targetDir)
+	}
+
+	//4. place the brick into the remote
+	if err := copyBrick(brick, targetDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("published %s %s to remote %s\n", brick.Id, brick.Version, remote.Name)
+	if remote.Kind == ports.GitRemote {
+		fmt.Printf("make sure to commit and push %s\n", targetDir)
+	}
+	return nil
+}
+
 var _ ports.BrickApi = BrickApi{}
 var _ ports.BrickUpgrader = BrickApi{}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -73,3 +76,252 @@ func TestBrickApi_Add(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateBrick(t *testing.T) {
+	tests := []struct {
+		name    string
+		brick   ports.Brick
+		wantErr bool
+	}{
+		{name: "valid", brick: ports.Brick{Id: "brick", Version: "1.0.0"}, wantErr: false},
+		{name: "missing id", brick: ports.Brick{Version: "1.0.0"}, wantErr: true},
+		{name: "missing version", brick: ports.Brick{Id: "brick"}, wantErr: true},
+		{name: "invalid version", brick: ports.Brick{Id: "brick", Version: "latest"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBrick(tt.brick); (err != nil) != tt.wantErr {
+				t.Errorf("validateBrick() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_checkVersionBump(t *testing.T) {
+	type args struct {
+		published ports.Brick
+		candidate ports.Brick
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{name: "bumped patch", args: args{published: ports.Brick{Id: "b", Version: "1.0.0"}, candidate: ports.Brick{Id: "b", Version: "1.0.1"}}, wantErr: false},
+		{name: "bumped major", args: args{published: ports.Brick{Id: "b", Version: "1.2.3"}, candidate: ports.Brick{Id: "b", Version: "2.0.0"}}, wantErr: false},
+		{name: "same version", args: args{published: ports.Brick{Id: "b", Version: "1.0.0"}, candidate: ports.Brick{Id: "b", Version: "1.0.0"}}, wantErr: true},
+		{name: "lower version", args: args{published: ports.Brick{Id: "b", Version: "1.1.0"}, candidate: ports.Brick{Id: "b", Version: "1.0.9"}}, wantErr: true},
+		{name: "invalid published version", args: args{published: ports.Brick{Id: "b", Version: "latest"}, candidate: ports.Brick{Id: "b", Version: "1.0.0"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkVersionBump(tt.args.published, tt.args.candidate); (err != nil) != tt.wantErr {
+				t.Errorf("checkVersionBump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBrickApi_List(t *testing.T) {
+	template := ports.Brick{Id: "template", Kind: ports.Template, Description: "some template"}
+	extension := ports.Brick{Id: "extension", Kind: ports.Extension, Description: "some extension"}
+	helper := ports.Brick{Id: "helper", Kind: ports.Helper, Description: "some helper"}
+	db := FakeBrickDB{"template": template, "extension": extension, "helper": helper}
+
+	tests := []struct {
+		name  string
+		kinds []ports.BrickKind
+		want  []ports.Brick
+	}{
+		{name: "extensions", kinds: []ports.BrickKind{ports.Extension}, want: []ports.Brick{extension}},
+		{name: "templates", kinds: []ports.BrickKind{ports.Template}, want: []ports.Brick{template}},
+		{name: "all", kinds: ports.BrickKinds, want: []ports.Brick{template, extension, helper}},
+		{name: "none", kinds: []ports.BrickKind{}, want: []ports.Brick{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BrickApi{
+				Configuration:  &MockConfiguration{},
+				BrickDBFactory: FakeBrickDBFactory{db: db},
+			}
+			if got := b.List(tt.kinds, false); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BrickApi.List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrickApi_Search(t *testing.T) {
+	template := ports.Brick{Id: "template", Kind: ports.Template, Description: "some template"}
+	extension := ports.Brick{Id: "extension", Kind: ports.Extension, Description: "some extension"}
+	helper := ports.Brick{Id: "helper", Kind: ports.Helper, Description: "some helper for extensions"}
+	db := FakeBrickDB{"template": template, "extension": extension, "helper": helper}
+
+	tests := []struct {
+		name  string
+		term  string
+		kinds []ports.BrickKind
+		want  []ports.Brick
+	}{
+		{name: "in id", term: "ext", kinds: []ports.BrickKind{ports.Extension}, want: []ports.Brick{extension}},
+		{name: "in description of all kinds", term: "extension", kinds: ports.BrickKinds, want: []ports.Brick{extension, helper}},
+		{name: "other kind", term: "template", kinds: []ports.BrickKind{ports.Extension}, want: []ports.Brick{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BrickApi{
+				Configuration:  &MockConfiguration{},
+				BrickDBFactory: FakeBrickDBFactory{db: db},
+			}
+			if got := b.Search(tt.term, tt.kinds); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BrickApi.Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrickApi_Which(t *testing.T) {
+	internal := ports.Remote{Name: "internal"}
+	public := ports.Remote{Name: "public"}
+	internalBrick := ports.Brick{Id: "a", Version: "1.0.0", Kind: ports.Extension}
+	publicBrickA := ports.Brick{Id: "a", Version: "2.0.0", Kind: ports.Extension}
+	publicBrickB := ports.Brick{Id: "b", Version: "1.0.0", Kind: ports.Extension}
+	factory := FakeBrickDBFactory{remotes: map[string]ports.BrickDB{
+		"internal": FakeBrickDB{"a": internalBrick},
+		"public":   FakeBrickDB{"a": publicBrickA, "b": publicBrickB},
+	}}
+
+	tests := []struct {
+		name    string
+		brickId string
+		pins    []ports.BrickPin
+		want    []ports.BrickOrigin
+		wantErr bool
+	}{
+		{name: "shadowed", brickId: "a", want: []ports.BrickOrigin{{Remote: internal, Brick: internalBrick}, {Remote: public, Brick: publicBrickA}}},
+		{name: "single origin", brickId: "b", want: []ports.BrickOrigin{{Remote: public, Brick: publicBrickB}}},
+		{name: "unknown", brickId: "c", want: nil, wantErr: true},
+		{name: "pinned", brickId: "a", pins: []ports.BrickPin{{Brick: "*", Remote: "public"}}, want: []ports.BrickOrigin{{Remote: public, Brick: publicBrickA, Pinned: true}, {Remote: internal, Brick: internalBrick}}},
+		{name: "pinned to remote without brick", brickId: "b", pins: []ports.BrickPin{{Brick: "b", Remote: "internal"}}, want: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BrickApi{
+				Configuration:  &MockConfiguration{remotes: []ports.Remote{internal, public}, pins: tt.pins},
+				BrickDBFactory: factory,
+			}
+			got, err := b.Which(tt.brickId)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BrickApi.Which() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BrickApi.Which() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrickApi_Pack(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		dependencies []string
+		outputDir    string //relative to the brick if not empty
+		wantErr      bool
+	}{
+		{name: "valid", content: "port <<<PORT>>>"},
+		{name: "undeclared parameter", content: "host <<<HOST>>>", wantErr: true},
+		{name: "unclosed section", content: "// <<<SAPPER SECTION BEGIN MAIN>>>", wantErr: true},
+		{name: "missing dependency", content: "port <<<PORT>>>", dependencies: []string{"missing"}, wantErr: true},
+		{name: "output inside the brick", content: "port <<<PORT>>>", outputDir: ".", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brickDir, _ := ioutil.TempDir("", "packBrick*")
+			defer os.RemoveAll(brickDir) // clean up
+			outputDir, _ := ioutil.TempDir("", "packOutput*")
+			defer os.RemoveAll(outputDir) // clean up
+			if tt.outputDir != "" {
+				outputDir = filepath.Join(brickDir, tt.outputDir)
+			}
+			ioutil.WriteFile(filepath.Join(brickDir, "manifest.yaml"), []byte("id: b\nversion: 1.0.0"), 0666)
+			ioutil.WriteFile(filepath.Join(brickDir, "file.txt"), []byte(tt.content), 0666)
+
+			brick := ports.Brick{Id: "b", Version: "1.0.0", Kind: ports.Extension, BasePath: brickDir, Files: []string{"file.txt"},
+				Parameters: []ports.BrickParameters{{Name: "PORT"}}, Dependencies: tt.dependencies}
+			b := BrickApi{
+				Configuration: &MockConfiguration{},
+				BrickDBFactory: FakeBrickDBFactory{makeBrickDB: func(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
+					return FakeBrickDB{"b": brick}, nil
+				}},
+				BrickPackager: FakeBrickPackager{},
+			}
+
+			pkg, err := b.Pack(brickDir, outputDir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BrickApi.Pack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, "b-1.0.0.tar.gz")); (err == nil) == tt.wantErr {
+				t.Errorf("BrickApi.Pack() package %s exists = %v, want %v", pkg.Path, err == nil, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBrickApi_Publish(t *testing.T) {
+	published := ports.Brick{Id: "b", Version: "1.0.0", Kind: ports.Extension}
+
+	tests := []struct {
+		name       string
+		version    string
+		extraFile  string
+		wantErr    bool
+		wantTarget string
+	}{
+		{name: "new version", version: "1.1.0", wantTarget: "b-1.1.0"},
+		{name: "already published", version: "1.0.0", wantErr: true},
+		{name: "undeclared file", version: "1.1.0", extraFile: "secret.env", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packageDir, _ := ioutil.TempDir("", "publishPackage*")
+			defer os.RemoveAll(packageDir) // clean up
+			remoteDir, _ := ioutil.TempDir("", "publishRemote*")
+			defer os.RemoveAll(remoteDir) // clean up
+			ioutil.WriteFile(filepath.Join(packageDir, "manifest.yaml"), []byte("id: b\nversion: "+tt.version), 0666)
+			ioutil.WriteFile(filepath.Join(packageDir, "file.txt"), []byte("some content"), 0666)
+			if tt.extraFile != "" {
+				ioutil.WriteFile(filepath.Join(packageDir, tt.extraFile), []byte("some secret"), 0666)
+			}
+
+			remote := ports.Remote{Name: "remote", Kind: ports.FilesystemRemote, Src: remoteDir}
+			b := BrickApi{
+				Configuration: &MockConfiguration{remotes: []ports.Remote{remote}},
+				BrickDBFactory: FakeBrickDBFactory{makeBrickDB: func(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
+					if r.Name == remote.Name {
+						return FakeBrickDB{"b": published}, nil
+					}
+					return FakeBrickDB{"b": ports.Brick{Id: "b", Version: tt.version, Kind: ports.Extension, BasePath: r.Src, Files: []string{"file.txt"}}}, nil
+				}},
+				BrickPackager: FakeBrickPackager{},
+			}
+
+			if err := b.Publish(packageDir, remote.Name); (err != nil) != tt.wantErr {
+				t.Errorf("BrickApi.Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			entries, _ := ioutil.ReadDir(remoteDir)
+			if tt.wantTarget == "" {
+				if len(entries) != 0 {
+					t.Errorf("BrickApi.Publish() placed %s into the remote", entries[0].Name())
+				}
+				return
+			}
+			for _, f := range []string{"manifest.yaml", "file.txt"} {
+				if _, err := os.Stat(filepath.Join(remoteDir, tt.wantTarget, f)); err != nil {
+					t.Errorf("BrickApi.Publish() did not place %s: %v", f, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+)
+
+var beginTagExp = regexp.MustCompile(`(<<<SAPPER\s*SECTION\s*BEGIN)\s*`)
+
+// skeleton returns the lines of a file without the content of its sections
+func skeleton(content string, sections []section) []string {
+	result := []string{}
+	contentLines := lines(content)
+	lineNumber := 0
+	for _, s := range sections {
+		result = append(result, contentLines[lineNumber:s.lineBegin]...)
+		lineNumber = s.lineEnd
+	}
+	return append(result, contentLines[lineNumber:]...)
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionFragment returns the verb and the content that turns the old content of a section into the new one
+func sectionFragment(oldLines []string, newLines []string) (verb string, content []string) {
+	if len(oldLines) <= len(newLines) && equalLines(oldLines, newLines[:len(oldLines)]) {
+		return "APPEND", newLines[len(oldLines):]
+	}
+
+	added := []string{}
+	for _, l := range oldLines {
+		if !containsLine(newLines, l) {
+			return "REPLACE", newLines
+		}
+	}
+	for _, l := range newLines {
+		if !containsLine(oldLines, l) {
+			added = append(added, l)
+		}
+	}
+	return "MERGE", added
+}
+
+// extractSections turns the changes within the sapper sections of a file into brick sections
+func extractSections(oldContent string, newContent string) (fragment string, warnings []string, err error) {
+	oldSections, err := readSections(oldContent)
+	if err != nil {
+		return "", nil, err
+	}
+	newSections, err := readSections(newContent)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !equalLines(skeleton(oldContent, oldSections), skeleton(newContent, newSections)) {
+		warnings = append(warnings, "changes outside of sapper sections cannot be extracted")
+	}
+
+	oldSectionMap := toMap(oldSections)
+	newContentLines := lines(newContent)
+	fragments := []string{}
+	for _, s := range newSections {
+		old, ok := oldSectionMap[s.name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("section %s has been added and cannot be extracted", s.name))
+			continue
+		}
+		if old.content == s.content {
+			continue
+		}
+
+		verb, content := sectionFragment(lines(old.content), lines(s.content))
+		if verb == "REPLACE" {
+			warnings = append(warnings, fmt.Sprintf("lines have been removed from section %s. The whole section is replaced", s.name))
+		}
+		if len(content) == 0 {
+			continue
+		}
+
+		beginTag := beginTagExp.ReplaceAllString(newContentLines[s.lineBegin-1], "$1 "+verb+" ")
+		fragment := append([]string{beginTag}, content...)
+		fragment = append(fragment, newContentLines[s.lineEnd])
+		fragments = append(fragments, toText(fragment))
+	}
+
+	if len(fragments) == 0 {
+		return "", warnings, nil
+	}
+	return strings.Join(fragments, fmt.Sprintln("")) + fmt.Sprintln(""), warnings, nil
+}
+
+// minParameterValueLength is the length below which a value is likely to match text that is unrelated to its parameter
+const minParameterValueLength = 3
+
+var wordExp = regexp.MustCompile(`\w`)
+
+// insertParameters substitutes the values of the parameters by their placeholders and returns the names of the parameters that have
+// been found. Only whole tokens are substituted, i.e. values that are not part of a longer word. Warnings are returned for
+// substitutions that may be wrong.
+func insertParameters(content string, parameters map[string]string) (string, []string, []string) {
+	names := []string{}
+	for name, value := range parameters {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	//longer values first so that values containing other values are substituted as a whole
+	sort.Slice(names, func(i, j int) bool {
+		if len(parameters[names[i]]) != len(parameters[names[j]]) {
+			return len(parameters[names[i]]) > len(parameters[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	warnings := []string{}
+	nameOfValue := map[string]string{}
+	alternatives := []string{}
+	for _, name := range names {
+		value := parameters[name]
+		if other, ok := nameOfValue[value]; ok {
+			warnings = append(warnings, fmt.Sprintf("parameters %s and %s have the same value '%s'. It is substituted by %s", other, name, value, other))
+			continue
+		}
+		nameOfValue[value] = name
+		alternatives = append(alternatives, tokenExp(value))
+	}
+	if len(alternatives) == 0 {
+		return content, []string{}, warnings
+	}
+
+	occurrences := map[string]int{}
+	content = regexp.MustCompile(strings.Join(alternatives, "|")).ReplaceAllStringFunc(content, func(value string) string {
+		occurrences[nameOfValue[value]]++
+		return "<<<" + nameOfValue[value] + ">>>"
+	})
+
+	found := []string{}
+	unsubstituted := placeholderExp.ReplaceAllString(content, "")
+	for _, name := range names {
+		value := parameters[name]
+		if occurrences[name] > 0 {
+			found = append(found, name)
+		}
+		if occurrences[name] > 0 && len(value) < minParameterValueLength {
+			warnings = append(warnings, fmt.Sprintf("the short value '%s' of parameter %s has been substituted %d time(s). Make sure that it refers to the parameter", value, name, occurrences[name]))
+		}
+		if strings.Contains(unsubstituted, value) {
+			warnings = append(warnings, fmt.Sprintf("the value '%s' of parameter %s is also part of other words which have not been substituted", value, name))
+		}
+	}
+	return content, found, warnings
+}
+
+// tokenExp returns a regular expression that matches a value unless it is part of a longer word
+func tokenExp(value string) string {
+	exp := regexp.QuoteMeta(value)
+	if wordExp.MatchString(value[:1]) {
+		exp = `\b` + exp
+	}
+	if wordExp.MatchString(value[len(value)-1:]) {
+		exp = exp + `\b`
+	}
+	return exp
+}
+
+// splitExtractionTarget splits a target of the form 'remote/id'
+func splitExtractionTarget(target string) (remoteName string, id string, err error) {
+	i := strings.Index(target, "/")
+	if i <= 0 || i == len(target)-1 || strings.Contains(target[i+1:], "/") {
+		return "", "", fmt.Errorf("invalid target %s. Must be of the form <remote>/<brick id>", target)
+	}
+	return target[:i], target[i+1:], nil
+}
+
+// isServiceMetadata tells whether a file of a service is maintained by sapper (e.g. the sapperfile, secrets, or vendored bricks)
+// rather than being part of the service's code
+func isServiceMetadata(path string) bool {
+	path = filepath.ToSlash(path)
+	return path == "sapperfile.yaml" || strings.HasPrefix(path, serviceMetadataDir+"/")
+}
+
+// Extract creates a new brick from the changes made to a service since a given revision
+func (b BrickApi) Extract(servicePath string, since string, target string, writer io.Writer) (brick ports.Brick, err error) {
+	remoteName, id, err := splitExtractionTarget(target)
+	if err != nil {
+		return ports.Brick{}, err
+	}
+	_, remote, ok := findRemote(b.Configuration.Remotes(), remoteName)
+	if !ok {
+		return ports.Brick{}, fmt.Errorf("remote %s does not exist", remoteName)
+	}
+	if remote.Kind != ports.FilesystemRemote {
+		return ports.Brick{}, fmt.Errorf("remote %s is not a filesystem remote", remoteName)
+	}
+	brickDir := filepath.Join(remote.Src, id)
+	if _, err := os.Stat(brickDir); !errors.Is(err, os.ErrNotExist) {
+		return ports.Brick{}, fmt.Errorf("%s already exists", brickDir)
+	}
+
+	service, err := b.ServicePersistence.Load(servicePath)
+	if err != nil {
+		return ports.Brick{}, err
+	}
+	changes, err := b.VersionControl.ChangedFiles(servicePath, since)
+	if err != nil {
+		return ports.Brick{}, err
+	}
+
+	files := map[string]string{}
+	usedParameters := map[string]bool{}
+	for _, change := range changes {
+		if isServiceMetadata(change.Path) {
+			continue
+		}
+		if change.Kind == ports.FileDeleted {
+			fmt.Fprintf(writer, "warning: %s has been deleted. Deleting files cannot be extracted\n", change.Path)
+			continue
+		}
+
+		newContent, err := ioutil.ReadFile(filepath.Join(servicePath, change.Path))
+		if err != nil {
+			return ports.Brick{}, err
+		}
+
+		content := string(newContent)
+		if change.Kind == ports.FileModified {
+			oldContent, err := b.VersionControl.FileContent(servicePath, since, change.Path)
+			if err != nil {
+				return ports.Brick{}, err
+			}
+			var warnings []string
+			content, warnings, err = extractSections(oldContent, content)
+			if err != nil {
+				return ports.Brick{}, fmt.Errorf("%s: %w", change.Path, err)
+			}
+			for _, w := range warnings {
+				fmt.Fprintf(writer, "warning: %s: %s\n", change.Path, w)
+			}
+			if content == "" {
+				continue
+			}
+		}
+
+		content, found, warnings := insertParameters(content, service.Parameters)
+		for _, w := range warnings {
+			for name := range service.Secrets { //do not show the values of secret parameters
+				if value := service.Parameters[name]; value != "" {
+					w = strings.ReplaceAll(w, "'"+value+"'", "'********'")
+				}
+			}
+			fmt.Fprintf(writer, "warning: %s: %s\n", change.Path, w)
+		}
+		for _, name := range found {
+			usedParameters[name] = true
+		}
+		files[change.Path] = content
+	}
+	if len(files) == 0 {
+		return ports.Brick{}, fmt.Errorf("no changes to extract since %s", since)
+	}
+
+	brick = ports.Brick{
+		Id:          id,
+		Description: fmt.Sprintf("extracted from service %s", service.Id),
+		Version:     "0.1.0",
+		Kind:        ports.Extension,
+		BasePath:    brickDir,
+	}
+	for name := range usedParameters {
+		if _, secret := service.Secrets[name]; secret { //the value of a secret must not end up in the manifest
+			brick.Parameters = append(brick.Parameters, ports.BrickParameters{Name: name, Secret: true})
+			continue
+		}
+		brick.Parameters = append(brick.Parameters, ports.BrickParameters{Name: name, Default: service.Parameters[name]})
+	}
+	sort.Slice(brick.Parameters, func(i, j int) bool { return brick.Parameters[i].Name < brick.Parameters[j].Name })
+
+	defer func() { //do not leave a partial brick behind
+		if err != nil {
+			os.RemoveAll(brickDir)
+		}
+	}()
+	for file, content := range files {
+		path := filepath.Join(brickDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return brick, err
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			return brick, err
+		}
+		brick.Files = append(brick.Files, file)
+	}
+	sort.Strings(brick.Files)
+
+	if err := b.ManifestWriter.WriteManifest(brick, brickDir); err != nil {
+		return brick, err
+	}
+	return brick, nil
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func Test_extractSections(t *testing.T) {
+	tests := []struct {
+		name         string
+		oldContent   string
+		newContent   string
+		wantFragment string
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{name: "unchanged",
+			oldContent:   "a\n//<<<SAPPER SECTION BEGIN S1>>>\nx\n//<<<SAPPER SECTION END S1>>>\n",
+			newContent:   "a\n//<<<SAPPER SECTION BEGIN S1>>>\nx\n//<<<SAPPER SECTION END S1>>>\n",
+			wantFragment: "",
+		},
+		{name: "lines appended",
+			oldContent:   "a\n//<<<SAPPER SECTION BEGIN S1>>>\nx\n//<<<SAPPER SECTION END S1>>>\n",
+			newContent:   "a\n//<<<SAPPER SECTION BEGIN S1>>>\nx\ny\nz\n//<<<SAPPER SECTION END S1>>>\n",
+			wantFragment: "//<<<SAPPER SECTION BEGIN APPEND S1>>>\ny\nz\n//<<<SAPPER SECTION END S1>>>\n",
+		},
+		{name: "lines inserted",
+			oldContent:   "#<<<SAPPER SECTION BEGIN S1>>>\nx\ny\n#<<<SAPPER SECTION END S1>>>\n",
+			newContent:   "#<<<SAPPER SECTION BEGIN S1>>>\nx\nz\ny\n#<<<SAPPER SECTION END S1>>>\n",
+			wantFragment: "#<<<SAPPER SECTION BEGIN MERGE S1>>>\nz\n#<<<SAPPER SECTION END S1>>>\n",
+		},
+		{name: "lines removed",
+			oldContent:   "//<<<SAPPER SECTION BEGIN S1>>>\nx\ny\n//<<<SAPPER SECTION END S1>>>\n",
+			newContent:   "//<<<SAPPER SECTION BEGIN S1>>>\nz\ny\n//<<<SAPPER SECTION END S1>>>\n",
+			wantFragment: "//<<<SAPPER SECTION BEGIN REPLACE S1>>>\nz\ny\n//<<<SAPPER SECTION END S1>>>\n",
+			wantWarnings: []string{"lines have been removed from section S1. The whole section is replaced"},
+		},
+		{name: "several sections and changes outside of sections",
+			oldContent:   "//<<<SAPPER SECTION BEGIN S1>>>\n//<<<SAPPER SECTION END S1>>>\na\n//<<<SAPPER SECTION BEGIN S2>>>\nx\n//<<<SAPPER SECTION END S2>>>\n",
+			newContent:   "//<<<SAPPER SECTION BEGIN S1>>>\nw\n//<<<SAPPER SECTION END S1>>>\nb\n//<<<SAPPER SECTION BEGIN S2>>>\nx\ny\n//<<<SAPPER SECTION END S2>>>\n",
+			wantFragment: "//<<<SAPPER SECTION BEGIN APPEND S1>>>\nw\n//<<<SAPPER SECTION END S1>>>\n//<<<SAPPER SECTION BEGIN APPEND S2>>>\ny\n//<<<SAPPER SECTION END S2>>>\n",
+			wantWarnings: []string{"changes outside of sapper sections cannot be extracted"},
+		},
+		{name: "section added",
+			oldContent:   "a\n",
+			newContent:   "a\n//<<<SAPPER SECTION BEGIN S1>>>\nx\n//<<<SAPPER SECTION END S1>>>\n",
+			wantFragment: "",
+			wantWarnings: []string{"changes outside of sapper sections cannot be extracted", "section S1 has been added and cannot be extracted"},
+		},
+		{name: "invalid sections",
+			oldContent: "",
+			newContent: "//<<<SAPPER SECTION END S1>>>\n",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFragment, gotWarnings, err := extractSections(tt.oldContent, tt.newContent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractSections() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotFragment != tt.wantFragment {
+				t.Errorf("extractSections() fragment = %q, want %q", gotFragment, tt.wantFragment)
+			}
+			if !reflect.DeepEqual(gotWarnings, tt.wantWarnings) {
+				t.Errorf("extractSections() warnings = %v, want %v", gotWarnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func Test_insertParameters(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		parameters   map[string]string
+		wantContent  string
+		wantFound    []string
+		wantWarnings []string
+	}{
+		{name: "no parameters", content: "abc", parameters: map[string]string{}, wantContent: "abc", wantFound: []string{}, wantWarnings: []string{}},
+		{name: "longer values first",
+			content:      "my-service listens on 8080 (my)",
+			parameters:   map[string]string{"NAME": "my-service", "PREFIX": "my", "PORT": "8080", "UNUSED": "xyz", "EMPTY": ""},
+			wantContent:  "<<<NAME>>> listens on <<<PORT>>> (<<<PREFIX>>>)",
+			wantFound:    []string{"NAME", "PORT", "PREFIX"},
+			wantWarnings: []string{"the short value 'my' of parameter PREFIX has been substituted 1 time(s). Make sure that it refers to the parameter"},
+		},
+		{name: "whole tokens only",
+			content:      "api.host = api.example.com; hostname = host",
+			parameters:   map[string]string{"HOST": "host", "DOMAIN": "example.com"},
+			wantContent:  "api.<<<HOST>>> = api.<<<DOMAIN>>>; hostname = <<<HOST>>>",
+			wantFound:    []string{"DOMAIN", "HOST"},
+			wantWarnings: []string{"the value 'host' of parameter HOST is also part of other words which have not been substituted"},
+		},
+		{name: "same value",
+			content:      "user: admin",
+			parameters:   map[string]string{"USER": "admin", "OWNER": "admin"},
+			wantContent:  "user: <<<OWNER>>>",
+			wantFound:    []string{"OWNER"},
+			wantWarnings: []string{"parameters OWNER and USER have the same value 'admin'. It is substituted by OWNER"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotContent, gotFound, gotWarnings := insertParameters(tt.content, tt.parameters)
+			if gotContent != tt.wantContent {
+				t.Errorf("insertParameters() content = %v, want %v", gotContent, tt.wantContent)
+			}
+			if !reflect.DeepEqual(gotFound, tt.wantFound) {
+				t.Errorf("insertParameters() found = %v, want %v", gotFound, tt.wantFound)
+			}
+			if !reflect.DeepEqual(gotWarnings, tt.wantWarnings) {
+				t.Errorf("insertParameters() warnings = %v, want %v", gotWarnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func Test_splitExtractionTarget(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantRemote string
+		wantId     string
+		wantErr    bool
+	}{
+		{target: "local/my-brick", wantRemote: "local", wantId: "my-brick"},
+		{target: "my-brick", wantErr: true},
+		{target: "local/", wantErr: true},
+		{target: "/my-brick", wantErr: true},
+		{target: "local/a/b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			gotRemote, gotId, err := splitExtractionTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("splitExtractionTarget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotRemote != tt.wantRemote || gotId != tt.wantId {
+				t.Errorf("splitExtractionTarget() = %v, %v, want %v, %v", gotRemote, gotId, tt.wantRemote, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestBrickApi_Extract(t *testing.T) {
+	serviceDir, _ := ioutil.TempDir("", "extractService*")
+	defer os.RemoveAll(serviceDir) // clean up
+	remoteDir, _ := ioutil.TempDir("", "extractRemote*")
+	defer os.RemoveAll(remoteDir) // clean up
+
+	oldMain := "int main() {\n  // <<<SAPPER SECTION BEGIN MAIN>>>\n  listen(8080);\n  // <<<SAPPER SECTION END MAIN>>>\n}\n"
+	newMain := "int main() {\n  // <<<SAPPER SECTION BEGIN MAIN>>>\n  listen(8080);\n  log(\"my-service started\");\n  // <<<SAPPER SECTION END MAIN>>>\n}\n"
+	os.MkdirAll(filepath.Join(serviceDir, "app"), 0777)
+	ioutil.WriteFile(filepath.Join(serviceDir, "app", "main.cpp"), []byte(newMain), 0666)
+	ioutil.WriteFile(filepath.Join(serviceDir, "metrics.cpp"), []byte("// metrics of my-service on port 8080 with token s3cr3t-token\n"), 0666)
+	ioutil.WriteFile(filepath.Join(serviceDir, "unchanged.cpp"), []byte("// unchanged\n"), 0666)
+
+	remote := ports.Remote{Name: "local", Kind: ports.FilesystemRemote, Src: remoteDir}
+	mw := &FakeManifestWriter{}
+	b := BrickApi{
+		Configuration: &MockConfiguration{remotes: []ports.Remote{remote}},
+		ServicePersistence: &FakeServicePersistence{service: ports.Service{Id: "my-service",
+			Parameters: map[string]string{"NAME": "my-service", "PORT": "8080", "TOKEN": "s3cr3t-token"},
+			Secrets:    map[string]string{"TOKEN": ports.DefaultSecretReference}}},
+		VersionControl: FakeVersionControl{
+			changes: []ports.FileChange{
+				{Path: "app/main.cpp", Kind: ports.FileModified},
+				{Path: "metrics.cpp", Kind: ports.FileAdded},
+				{Path: "obsolete.cpp", Kind: ports.FileDeleted},
+				{Path: "sapperfile.yaml", Kind: ports.FileModified},
+				{Path: ".sapper/.gitignore", Kind: ports.FileAdded},
+				{Path: ".sapper/bricks/other-1.0.0/manifest.yaml", Kind: ports.FileAdded},
+			},
+			contents: map[string]string{"app/main.cpp": oldMain},
+		},
+		ManifestWriter: mw,
+	}
+
+	var output bytes.Buffer
+	brick, err := b.Extract(serviceDir, "HEAD~1", "local/metrics", &output)
+	if err != nil {
+		t.Fatalf("BrickApi.Extract() error = %v", err)
+	}
+
+	want := ports.Brick{Id: "metrics", Description: "extracted from service my-service", Version: "0.1.0", Kind: ports.Extension,
+		BasePath:   filepath.Join(remoteDir, "metrics"),
+		Files:      []string{"app/main.cpp", "metrics.cpp"},
+		Parameters: []ports.BrickParameters{{Name: "NAME", Default: "my-service"}, {Name: "PORT", Default: "8080"}, {Name: "TOKEN", Secret: true}},
+	}
+	if !reflect.DeepEqual(brick, want) {
+		t.Errorf("BrickApi.Extract() = %v, want %v", brick, want)
+	}
+	if !reflect.DeepEqual(mw.bricks, []ports.Brick{want}) {
+		t.Errorf("BrickApi.Extract() wrote manifests %v, want %v", mw.bricks, []ports.Brick{want})
+	}
+	wantFiles := map[string]string{
+		"app/main.cpp": "  // <<<SAPPER SECTION BEGIN APPEND MAIN>>>\n  log(\"<<<NAME>>> started\");\n  // <<<SAPPER SECTION END MAIN>>>\n",
+		"metrics.cpp":  "// metrics of <<<NAME>>> on port <<<PORT>>> with token <<<TOKEN>>>\n",
+	}
+	for file, wantContent := range wantFiles {
+		if content, _ := ioutil.ReadFile(filepath.Join(remoteDir, "metrics", file)); string(content) != wantContent {
+			t.Errorf("BrickApi.Extract() %s = %q, want %q", file, string(content), wantContent)
+		}
+	}
+	if !strings.Contains(output.String(), "warning: obsolete.cpp has been deleted") {
+		t.Errorf("BrickApi.Extract() output = %q, want a warning about the deleted file", output.String())
+	}
+
+	if _, err := b.Extract(serviceDir, "HEAD~1", "local/metrics", &output); err == nil {
+		t.Errorf("BrickApi.Extract() error = nil, want an error for an existing brick")
+	}
+
+	//a brick that cannot be written completely is removed
+	b.ManifestWriter = &FakeManifestWriter{err: errors.New("disk full")}
+	if _, err := b.Extract(serviceDir, "HEAD~1", "local/failing", &output); err == nil {
+		t.Errorf("BrickApi.Extract() error = nil, want the error of the manifest writer")
+	}
+	if _, err := os.Stat(filepath.Join(remoteDir, "failing")); !os.IsNotExist(err) {
+		t.Errorf("BrickApi.Extract() left the partially written brick behind")
+	}
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/seboste/sapper/ports"
+	pr "github.com/seboste/sapper/utils/parameter-resolver"
+)
+
+// brickCombinations returns each brick on its own followed by all pairwise combinations if requested
+func brickCombinations(brickIds []string, pairs bool) [][]string {
+	combinations := [][]string{}
+	for _, id := range brickIds {
+		combinations = append(combinations, []string{id})
+	}
+	if !pairs {
+		return combinations
+	}
+	for i := range brickIds {
+		for j := i + 1; j < len(brickIds); j++ {
+			combinations = append(combinations, []string{brickIds[i], brickIds[j]})
+		}
+	}
+	return combinations
+}
+
+func containsBrick(service ports.Service, brickId string) bool {
+	for _, d := range service.BrickIds {
+		if d.Id == brickId {
+			return true
+		}
+	}
+	return false
+}
+
+// testBricks creates a temp service from the template, adds the bricks, and builds and tests the service
+func (b BrickApi) testBricks(template string, brickIds []string) ports.BrickTestResult {
+	result := ports.BrickTestResult{BrickIds: brickIds, Status: ports.BrickTestSetupFailed}
+
+	parentDir, err := ioutil.TempDir("", "sapper_test_*")
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	defer os.RemoveAll(parentDir)
+
+	service, err := b.ServiceApi.Add(template, parentDir, pr.DummyParameterResolver{})
+	if err != nil {
+		result.Message = fmt.Sprintf("unable to create service from template %s: %v", template, err)
+		return result
+	}
+	for _, brickId := range brickIds {
+		service, err = b.ServicePersistence.Load(service.Path)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		if containsBrick(service, brickId) { //e.g. the other brick of a pair depends on it
+			continue
+		}
+		if err := b.Add(service.Path, brickId, pr.DummyParameterResolver{}); err != nil {
+			result.Message = fmt.Sprintf("unable to add brick %s: %v", brickId, err)
+			return result
+		}
+	}
+
+	if buildLogFilename, err := b.ServiceApi.Build(service.Path); err != nil {
+		result.Status = ports.BrickTestBuildFailed
+		result.Message = fmt.Sprintf("%v (see %s for details)", err, buildLogFilename)
+		return result
+	}
+
+	var testOutput bytes.Buffer
+	if err := b.ServiceApi.test(service.Path, &testOutput); err != nil {
+		result.Status = ports.BrickTestTestFailed
+		result.Message = err.Error()
+		if f, err := ioutil.TempFile("", "sapper_test_log_*.log"); err == nil {
+			f.Write(testOutput.Bytes())
+			f.Close()
+			result.Message = fmt.Sprintf("%s (see %s for details)", result.Message, f.Name())
+		}
+		return result
+	}
+
+	result.Status = ports.BrickTestPassed
+	return result
+}
+
+// Test builds and tests each brick on its own and optionally all pairs of bricks in a service created from the template
+func (b BrickApi) Test(brickIds []string, template string, pairs bool, writer io.Writer) (ports.BrickTestReport, error) {
+	report := ports.BrickTestReport{Template: template}
+
+	db, err := b.BrickDBFactory.MakeAggregatedBrickDB(b.Configuration.Remotes(), b.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return report, err
+	}
+	if _, err := findBrick(db, template); err != nil {
+		return report, err
+	}
+	if len(brickIds) == 0 {
+		for _, brick := range db.Bricks(ports.Extension) {
+			brickIds = append(brickIds, brick.Id)
+		}
+	}
+	for _, brickId := range brickIds {
+		if _, err := findBrick(db, brickId); err != nil {
+			return report, err
+		}
+	}
+	report.BrickIds = brickIds
+
+	for _, combination := range brickCombinations(brickIds, pairs) {
+		fmt.Fprintf(writer, "testing %s...", strings.Join(combination, " + "))
+		start := time.Now()
+		result := b.testBricks(template, combination)
+		result.Duration = time.Since(start)
+		fmt.Fprintf(writer, "%s (%v)\n", result.Status, result.Duration.Round(time.Second))
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func Test_brickCombinations(t *testing.T) {
+	tests := []struct {
+		name     string
+		brickIds []string
+		pairs    bool
+		want     [][]string
+	}{
+		{name: "none", brickIds: []string{}, pairs: true, want: [][]string{}},
+		{name: "singles", brickIds: []string{"a", "b", "c"}, pairs: false, want: [][]string{{"a"}, {"b"}, {"c"}}},
+		{name: "pairs", brickIds: []string{"a", "b", "c"}, pairs: true, want: [][]string{{"a"}, {"b"}, {"c"}, {"a", "b"}, {"a", "c"}, {"b", "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := brickCombinations(tt.brickIds, tt.pairs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("brickCombinations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTestBrick writes a brick with a manifest and the given files into dir
+func writeTestBrick(dir string, brick ports.Brick, files map[string]string) ports.Brick {
+	brick.BasePath = filepath.Join(dir, brick.Id)
+	os.MkdirAll(brick.BasePath, os.ModePerm)
+	ioutil.WriteFile(filepath.Join(brick.BasePath, "manifest.yaml"), []byte("id: "+brick.Id+"\nversion: "+brick.Version), 0666)
+	for f, content := range files {
+		ioutil.WriteFile(filepath.Join(brick.BasePath, f), []byte(content), 0666)
+		brick.Files = append(brick.Files, f)
+	}
+	return brick
+}
+
+func TestBrickApi_Test(t *testing.T) {
+	brickDir := t.TempDir()
+	mainCpp := "int main() {\n  // <<<SAPPER SECTION BEGIN MAIN>>>\n  // <<<SAPPER SECTION END MAIN>>>\n}\n"
+	extension := func(id string) ports.Brick {
+		return writeTestBrick(brickDir, ports.Brick{Id: id, Version: "1.0.0", Kind: ports.Extension}, map[string]string{
+			"main.cpp":  "  // <<<SAPPER SECTION BEGIN APPEND MAIN>>>\n  " + id + "();\n  // <<<SAPPER SECTION END APPEND MAIN>>>\n",
+			id + ".cpp": "void " + id + "() {}\n",
+		})
+	}
+	db := FakeBrickDB{
+		"tmpl":        writeTestBrick(brickDir, ports.Brick{Id: "tmpl", Version: "1.0.0", Kind: ports.Template, Parameters: []ports.BrickParameters{{Name: "NAME", Default: "svc"}}}, map[string]string{"main.cpp": mainCpp}),
+		"good":        extension("good"),
+		"other":       extension("other"),
+		"build-error": extension("build-error"),
+		"test-error":  extension("test-error"),
+	}
+
+	tests := []struct {
+		name       string
+		brickIds   []string
+		template   string
+		pairs      bool
+		want       map[string]ports.BrickTestStatus //bricks joined by '+'->status
+		wantErr    bool
+		wantOutput []string
+	}{
+		{name: "passing pair", brickIds: []string{"good", "other"}, template: "tmpl", pairs: true,
+			want:       map[string]ports.BrickTestStatus{"good": ports.BrickTestPassed, "other": ports.BrickTestPassed, "good+other": ports.BrickTestPassed},
+			wantOutput: []string{"testing good + other...passed"},
+		},
+		{name: "failing build", brickIds: []string{"good", "build-error"}, template: "tmpl", pairs: true,
+			want: map[string]ports.BrickTestStatus{"good": ports.BrickTestPassed, "build-error": ports.BrickTestBuildFailed, "good+build-error": ports.BrickTestBuildFailed},
+		},
+		{name: "failing test", brickIds: []string{"test-error"}, template: "tmpl",
+			want:       map[string]ports.BrickTestStatus{"test-error": ports.BrickTestTestFailed},
+			wantOutput: []string{"testing test-error...test failed"},
+		},
+		{name: "setup failure", brickIds: []string{"good"}, template: "good",
+			want: map[string]ports.BrickTestStatus{"good": ports.BrickTestSetupFailed},
+		},
+		{name: "unknown brick", brickIds: []string{"missing"}, template: "tmpl", wantErr: true},
+		{name: "unknown template", brickIds: []string{"good"}, template: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &FakeServicePersistence{}
+			b := BrickApi{
+				Configuration:      &MockConfiguration{},
+				BrickDBFactory:     FakeBrickDBFactory{db: db},
+				ServicePersistence: sp,
+				ServiceApi: ServiceApi{
+					Configuration:      &MockConfiguration{},
+					BrickDBFactory:     FakeBrickDBFactory{db: db},
+					ServicePersistence: sp,
+					ServiceBuilder:     FakeServiceBuilder{failingBuilds: map[string]bool{"build-error": true}, failingTests: map[string]bool{"test-error": true}},
+					Stdout:             ioutil.Discard,
+					Stderr:             ioutil.Discard,
+				},
+			}
+
+			var output bytes.Buffer
+			report, err := b.Test(tt.brickIds, tt.template, tt.pairs, &output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BrickApi.Test() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := map[string]ports.BrickTestStatus{}
+			for _, r := range report.Results {
+				got[strings.Join(r.BrickIds, "+")] = r.Status
+				if (r.Status == ports.BrickTestPassed) != (r.Message == "") {
+					t.Errorf("BrickApi.Test() result %v has status %v and message %q", r.BrickIds, r.Status, r.Message)
+				}
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BrickApi.Test() results = %v, want %v", got, tt.want)
+			}
+			for _, o := range tt.wantOutput {
+				if !strings.Contains(output.String(), o) {
+					t.Errorf("BrickApi.Test() output = %q, want it to contain %q", output.String(), o)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type brickIssues struct {
+	errors   []string
+	warnings []string
+}
+
+func (bi *brickIssues) addError(format string, a ...interface{}) {
+	bi.errors = append(bi.errors, fmt.Sprintf(format, a...))
+}
+
+func (bi *brickIssues) addWarning(format string, a ...interface{}) {
+	bi.warnings = append(bi.warnings, fmt.Sprintf(format, a...))
+}
+
+var placeholderExp = regexp.MustCompile(`<<<([^<>\s]+)>>>`)
+
+// verifySections checks that all section tags in the content can be parsed and are properly nested
+func verifySections(content string, file string, issues *brickIssues) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	openSection := ""
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if !strings.Contains(line, "<<<SAPPER") {
+			continue
+		}
+		t := readTag(line)
+		if t == nil {
+			issues.addError("%s:%d: malformed section tag '%s'", file, lineNumber, strings.TrimSpace(line))
+			continue
+		}
+		if t.name == "" {
+			issues.addError("%s:%d: section tag without a name", file, lineNumber)
+		}
+		if t.begin {
+			openSection = t.name
+		} else {
+			openSection = ""
+		}
+	}
+	if _, err := readSections(content); err != nil {
+		issues.addError("%s: %v", file, err)
+	} else if openSection != "" {
+		issues.addError("%s: section %s is not closed", file, openSection)
+	}
+}
+
+// verifyBrick checks a single brick. db is used to resolve the brick's dependencies.
+func verifyBrick(brick ports.Brick, db ports.BrickDB) brickIssues {
+	issues := brickIssues{}
+
+	if err := validateBrick(brick); err != nil {
+		issues.addError("%v", err)
+	}
+
+	//1. dependencies
+	declaredParameters := map[string]bool{} //parameters of the brick and its dependencies
+	for _, p := range brick.Parameters {
+		declaredParameters[p.Name] = true
+	}
+	for _, dependency := range brick.Dependencies {
+		if _, err := findBrick(db, dependency); err != nil {
+			issues.addError("dependency %s does not exist", dependency)
+			continue
+		}
+		bricks, err := GetBricksRecursive(dependency, db, map[string]bool{brick.Id: true})
+		if errors.Is(err, CyclicBrickDependency) {
+			issues.addError("dependency %s: %v", dependency, err)
+		}
+		for _, b := range bricks {
+			for _, p := range b.Parameters {
+				declaredParameters[p.Name] = true
+			}
+		}
+	}
+
+	//2. files
+	usedParameters := map[string]bool{}
+	for _, f := range brick.Files {
+		content, err := ioutil.ReadFile(filepath.Join(brick.BasePath, f))
+		if err != nil {
+			issues.addError("%v", err)
+			continue
+		}
+		contentStr := string(content)
+		for _, m := range placeholderExp.FindAllStringSubmatch(contentStr, -1) {
+			if !declaredParameters[m[1]] && !usedParameters[m[1]] {
+				issues.addError("%s: parameter %s is used but not declared", f, m[1])
+			}
+			usedParameters[m[1]] = true
+		}
+		verifySections(contentStr, f, &issues)
+	}
+
+	//3. unused parameters
+	for _, p := range brick.Parameters {
+		if !usedParameters[p.Name] {
+			issues.addWarning("parameter %s is declared but never used", p.Name)
+		}
+	}
+
+	return issues
+}
+
+// verifyBricks checks all bricks of db and all manifests that could not be read.
+// dependencyDb is used to resolve dependencies. The result is sorted by brick id.
+func verifyBricks(db ports.BrickDB, dependencyDb ports.BrickDB) (ids []string, issues map[string]brickIssues) {
+	issues = map[string]brickIssues{}
+	for _, invalidManifest := range db.InvalidManifests() {
+		bi := brickIssues{}
+		for _, line := range strings.Split(invalidManifest.Err.Error(), "\n") {
+			bi.addError("%s", line)
+		}
+		issues[invalidManifest.Path] = bi
+	}
+	for _, brick := range allBricks(db) {
+		for _, version := range db.BrickVersions(brick.Id) {
+			issues[fmt.Sprintf("%s %s", version.Id, version.Version)] = verifyBrick(version, dependencyDb)
+		}
+	}
+	for id := range issues {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, issues
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func Test_verifySections(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantErrors int
+	}{
+		{name: "no sections", content: "some content\n", wantErrors: 0},
+		{name: "valid section", content: `<<<SAPPER SECTION BEGIN APPEND my_section>>>
+content
+<<<SAPPER SECTION END APPEND my_section>>>
+`, wantErrors: 0},
+		{name: "malformed tag", content: `<<<SAPPER SECTION START my_section>>>
+`, wantErrors: 1},
+		{name: "mismatching end tag", content: `<<<SAPPER SECTION BEGIN my_section>>>
+<<<SAPPER SECTION END other_section>>>
+`, wantErrors: 1},
+		{name: "unclosed section", content: `<<<SAPPER SECTION BEGIN my_section>>>
+content
+`, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := brickIssues{}
+			verifySections(tt.content, "file.txt", &issues)
+			if len(issues.errors) != tt.wantErrors {
+				t.Errorf("verifySections() errors = %v, want %d errors", issues.errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func Test_verifyBrick(t *testing.T) {
+	brickDir, _ := ioutil.TempDir("", "verifyBrick*")
+	defer os.RemoveAll(brickDir) // clean up
+	ioutil.WriteFile(filepath.Join(brickDir, "a.txt"), []byte("name: <<<NAME>>>, port: <<<PORT>>>"), 0666)
+
+	base := ports.Brick{Id: "base", Version: "1.0.0", Parameters: []ports.BrickParameters{{Name: "NAME"}}}
+	cycleA := ports.Brick{Id: "cycle_a", Version: "1.0.0", Dependencies: []string{"cycle_b"}}
+	cycleB := ports.Brick{Id: "cycle_b", Version: "1.0.0", Dependencies: []string{"cycle_a"}}
+	db := FakeBrickDB{"base": base, "cycle_a": cycleA, "cycle_b": cycleB}
+
+	tests := []struct {
+		name         string
+		brick        ports.Brick
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{name: "valid",
+			brick: ports.Brick{Id: "b", Version: "1.0.0", BasePath: brickDir, Files: []string{"a.txt"}, Dependencies: []string{"base"}, Parameters: []ports.BrickParameters{{Name: "PORT"}}},
+		},
+		{name: "invalid version",
+			brick:      ports.Brick{Id: "b", Version: "latest"},
+			wantErrors: []string{"brick b has an invalid version: latest does not follow the format '<prefix><major>.<minor>.<patch><suffix> where major, minor, and patch must be digits'"},
+		},
+		{name: "missing dependency",
+			brick:      ports.Brick{Id: "b", Version: "1.0.0", Dependencies: []string{"missing"}},
+			wantErrors: []string{"dependency missing does not exist"},
+		},
+		{name: "cyclic dependency",
+			brick:      cycleA,
+			wantErrors: []string{"dependency cycle_b: cyclic brick dependency"},
+		},
+		{name: "undeclared parameter",
+			brick:      ports.Brick{Id: "b", Version: "1.0.0", BasePath: brickDir, Files: []string{"a.txt"}, Dependencies: []string{"base"}},
+			wantErrors: []string{"a.txt: parameter PORT is used but not declared"},
+		},
+		{name: "unused parameter",
+			brick:        ports.Brick{Id: "b", Version: "1.0.0", BasePath: brickDir, Files: []string{"a.txt"}, Parameters: []ports.BrickParameters{{Name: "NAME"}, {Name: "PORT"}, {Name: "UNUSED"}}},
+			wantWarnings: []string{"parameter UNUSED is declared but never used"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := verifyBrick(tt.brick, db)
+			if !reflect.DeepEqual(got.errors, tt.wantErrors) {
+				t.Errorf("verifyBrick() errors = %v, want %v", got.errors, tt.wantErrors)
+			}
+			if !reflect.DeepEqual(got.warnings, tt.wantWarnings) {
+				t.Errorf("verifyBrick() warnings = %v, want %v", got.warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
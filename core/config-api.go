@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type ConfigApi struct {
+	Configuration ports.Configuration
+	Editor        ports.ConfigurationEditor
+}
+
+func (c ConfigApi) Parameters() map[string]string {
+	return c.Configuration.Parameters()
+}
+
+// List returns all effective configuration values along with their source
+func (c ConfigApi) List() []ports.ConfigurationValue {
+	return c.Editor.Values()
+}
+
+func (c ConfigApi) Get(key string) (ports.ConfigurationValue, error) {
+	for _, v := range c.Editor.Values() {
+		if v.Key == key {
+			return v, nil
+		}
+	}
+	return ports.ConfigurationValue{}, fmt.Errorf("%s is not set", key)
+}
+
+// Set validates and stores a value of the user's configuration
+func (c ConfigApi) Set(key string, value string) error {
+	if err := c.Editor.SetValue(key, value); err != nil {
+		return err
+	}
+	return c.Editor.Save()
+}
+
+func (c ConfigApi) Unset(key string) error {
+	if err := c.Editor.UnsetValue(key); err != nil {
+		return err
+	}
+	return c.Editor.Save()
+}
+
+// Path returns the path of the user's configuration file
+func (c ConfigApi) Path() string {
+	return c.Editor.ConfigPath()
+}
+
+var _ ports.ConfigApi = ConfigApi{}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type MockConfigurationEditor struct {
+	values     []ports.ConfigurationValue
+	saveCalled bool
+}
+
+func (e *MockConfigurationEditor) Save() error {
+	e.saveCalled = true
+	return nil
+}
+
+func (e MockConfigurationEditor) ConfigPath() string {
+	return "/config.yaml"
+}
+
+func (e MockConfigurationEditor) Values() []ports.ConfigurationValue {
+	return e.values
+}
+
+func (e *MockConfigurationEditor) SetValue(key string, value string) error {
+	if key != "RemoteUpdateTTL" {
+		return fmt.Errorf("unknown key %s", key)
+	}
+	e.values = append(e.values, ports.ConfigurationValue{Key: key, Value: value, Source: ports.UserConfiguration})
+	return nil
+}
+
+func (e *MockConfigurationEditor) UnsetValue(key string) error {
+	return fmt.Errorf("not supported")
+}
+
+var _ ports.ConfigurationEditor = (*MockConfigurationEditor)(nil)
+
+func TestConfigApi_Get(t *testing.T) {
+	ttl := ports.ConfigurationValue{Key: "RemoteUpdateTTL", Value: "24h", Source: ports.ProjectConfiguration}
+	tests := []struct {
+		name    string
+		key     string
+		want    ports.ConfigurationValue
+		wantErr bool
+	}{
+		{name: "set", key: "RemoteUpdateTTL", want: ttl},
+		{name: "not set", key: "Parameters.PORT", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ConfigApi{Editor: &MockConfigurationEditor{values: []ports.ConfigurationValue{ttl}}}
+			got, err := c.Get(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConfigApi.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ConfigApi.Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigApi_Set(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		wantErr        bool
+		wantSaveCalled bool
+	}{
+		{name: "valid key", key: "RemoteUpdateTTL", wantSaveCalled: true},
+		{name: "invalid key", key: "Foo", wantErr: true, wantSaveCalled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := MockConfigurationEditor{}
+			c := ConfigApi{Editor: &e}
+			if err := c.Set(tt.key, "1h"); (err != nil) != tt.wantErr {
+				t.Errorf("ConfigApi.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if e.saveCalled != tt.wantSaveCalled {
+				t.Errorf("ConfigApi.Set() saveCalled = %v, wantSaveCalled %v", e.saveCalled, tt.wantSaveCalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/seboste/sapper/ports"
+)
+
+// FakeBrickDB is an in-memory brick db that provides a single version of each brick
+type FakeBrickDB map[string]ports.Brick //id->brick
+
+func (db FakeBrickDB) Bricks(kind ports.BrickKind) []ports.Brick {
+	bricks := []ports.Brick{}
+	for _, b := range db {
+		if b.Kind == kind {
+			bricks = append(bricks, b)
+		}
+	}
+	return bricks
+}
+
+func (db FakeBrickDB) Brick(id string) (ports.Brick, error) {
+	if b, ok := db[id]; ok {
+		return b, nil
+	}
+	return ports.Brick{}, ports.BrickNotFound
+}
+
+func (db FakeBrickDB) BrickVersions(id string) []ports.Brick {
+	if b, ok := db[id]; ok {
+		return []ports.Brick{b}
+	}
+	return []ports.Brick{}
+}
+
+func (db FakeBrickDB) BrickMatching(id string, constraint ports.VersionConstraint) (ports.Brick, error) {
+	if b, ok := db[id]; ok && constraint.Matches(b.Version) {
+		return b, nil
+	}
+	return ports.Brick{}, ports.BrickNotFound
+}
+
+func (db FakeBrickDB) Update(output io.Writer) error {
+	return nil
+}
+
+func (db FakeBrickDB) InvalidManifests() []ports.BrickManifestError {
+	return nil
+}
+
+func (db FakeBrickDB) LastUpdate() time.Time {
+	return time.Time{}
+}
+
+func (db FakeBrickDB) IsModified() (bool, string) {
+	return false, ""
+}
+
+var _ ports.BrickDB = FakeBrickDB{}
+
+// FakeAggregatedBrickDB provides the bricks of FakeBrickDB and the brick dbs of the remotes
+type FakeAggregatedBrickDB struct {
+	FakeBrickDB
+	remotes []ports.BrickDB
+}
+
+func (db FakeAggregatedBrickDB) RemoteBrickDBs() []ports.BrickDB {
+	return db.remotes
+}
+
+var _ ports.AggregatedBrickDB = FakeAggregatedBrickDB{}
+
+// FakeBrickDBFactory provides the brick db of a remote. makeBrickDB takes precedence over the db of the remote in remotes, which
+// takes precedence over db. db is also the aggregated brick db. Without db, the aggregated brick db consists of the brick dbs of
+// the remotes.
+type FakeBrickDBFactory struct {
+	db          ports.BrickDB
+	remotes     map[string]ports.BrickDB //remote name->db
+	makeBrickDB func(r ports.Remote, remotesDir string) (ports.BrickDB, error)
+}
+
+func (f FakeBrickDBFactory) MakeBrickDB(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
+	if f.makeBrickDB != nil {
+		return f.makeBrickDB(r, remotesDir)
+	}
+	if db, ok := f.remotes[r.Name]; ok {
+		return db, nil
+	}
+	if f.db != nil {
+		return f.db, nil
+	}
+	return nil, fmt.Errorf("remote %s is not reachable", r.Name)
+}
+
+func (f FakeBrickDBFactory) MakeAggregatedBrickDB(r []ports.Remote, remotesDir string) (ports.BrickDB, error) {
+	if f.db != nil {
+		return f.db, nil
+	}
+	adb := FakeAggregatedBrickDB{FakeBrickDB: FakeBrickDB{}}
+	for _, remote := range r {
+		db, err := f.MakeBrickDB(remote, remotesDir)
+		if err != nil {
+			return nil, err
+		}
+		adb.remotes = append(adb.remotes, db)
+	}
+	return adb, nil
+}
+
+var _ ports.BrickDBFactory = FakeBrickDBFactory{}
+
+// FakeServicePersistence keeps a single service in memory
+type FakeServicePersistence struct {
+	service ports.Service
+}
+
+func (p *FakeServicePersistence) Load(path string) (ports.Service, error) {
+	service := p.service
+	service.Path = path
+	return service, nil
+}
+
+func (p *FakeServicePersistence) Save(service ports.Service) error {
+	p.service = service
+	return nil
+}
+
+var _ ports.ServicePersistence = (*FakeServicePersistence)(nil)
+
+// FakeBrickPackager uses directories as packages
+type FakeBrickPackager struct{}
+
+func (p FakeBrickPackager) Pack(b ports.Brick, packagePath string) error {
+	return copyBrick(b, packagePath)
+}
+
+func (p FakeBrickPackager) Unpack(packagePath string, targetDir string) error {
+	return filepath.Walk(packagePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(packagePath, path)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(targetDir, rel)), os.ModePerm); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(targetDir, rel), content, 0644)
+	})
+}
+
+var _ ports.BrickPackager = FakeBrickPackager{}
+
+// FakeVersionControl provides the changed files and the previous contents of the modified files
+type FakeVersionControl struct {
+	changes  []ports.FileChange
+	contents map[string]string //file->content at the revision
+}
+
+func (vc FakeVersionControl) ChangedFiles(path string, since string) ([]ports.FileChange, error) {
+	return vc.changes, nil
+}
+
+func (vc FakeVersionControl) FileContent(path string, revision string, file string) (string, error) {
+	if content, ok := vc.contents[file]; ok {
+		return content, nil
+	}
+	return "", fmt.Errorf("%s does not exist in revision %s", file, revision)
+}
+
+var _ ports.VersionControl = FakeVersionControl{}
+
+// FakeManifestWriter keeps the bricks whose manifests have been written. It fails with err if set.
+type FakeManifestWriter struct {
+	bricks []ports.Brick
+	err    error
+}
+
+func (w *FakeManifestWriter) WriteManifest(b ports.Brick, dir string) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.bricks = append(w.bricks, b)
+	return nil
+}
+
+var _ ports.BrickManifestWriter = (*FakeManifestWriter)(nil)
+
+// FakeServiceBuilder fails to build services that contain one of the bricks in failingBuilds and fails the tests of services
+// that contain one of the bricks in failingTests
+type FakeServiceBuilder struct {
+	failingBuilds map[string]bool
+	failingTests  map[string]bool
+}
+
+func (sb FakeServiceBuilder) fail(service ports.Service, failing map[string]bool, output io.Writer) error {
+	for _, d := range service.BrickIds {
+		if failing[d.Id] {
+			fmt.Fprintf(output, "%s is broken\n", d.Id)
+			return fmt.Errorf("exit status 1")
+		}
+	}
+	return nil
+}
+
+func (sb FakeServiceBuilder) Build(service ports.Service, output io.Writer) error {
+	return sb.fail(service, sb.failingBuilds, output)
+}
+
+func (sb FakeServiceBuilder) Run(service ports.Service, output io.Writer) error {
+	return nil
+}
+
+func (sb FakeServiceBuilder) Deploy(service ports.Service, output io.Writer) error {
+	return nil
+}
+
+func (sb FakeServiceBuilder) Test(service ports.Service, output io.Writer) error {
+	return sb.fail(service, sb.failingTests, output)
+}
+
+func (sb FakeServiceBuilder) Stop(service ports.Service, output io.Writer) error {
+	return nil
+}
+
+var _ ports.ServiceBuilder = FakeServiceBuilder{}
@@ -0,0 +1,35 @@
+package core
+
+import "github.com/seboste/sapper/ports"
+
+type ProfileApi struct {
+	Configuration ports.ProfileConfiguration
+}
+
+func (p ProfileApi) List() (profiles []string, active string) {
+	return p.Configuration.ProfileNames(), p.Configuration.ActiveProfile()
+}
+
+// Use activates a profile for all subsequent commands
+func (p ProfileApi) Use(name string) error {
+	if err := p.Configuration.UseProfile(name); err != nil {
+		return err
+	}
+	return p.Configuration.Save()
+}
+
+func (p ProfileApi) Add(name string) error {
+	if err := p.Configuration.AddProfile(name); err != nil {
+		return err
+	}
+	return p.Configuration.Save()
+}
+
+func (p ProfileApi) Remove(name string) error {
+	if err := p.Configuration.RemoveProfile(name); err != nil {
+		return err
+	}
+	return p.Configuration.Save()
+}
+
+var _ ports.ProfileApi = ProfileApi{}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+type MockProfileConfiguration struct {
+	profiles   []string
+	active     string
+	saveCalled bool
+}
+
+func (c *MockProfileConfiguration) Save() error {
+	c.saveCalled = true
+	return nil
+}
+
+func (c MockProfileConfiguration) ActiveProfile() string {
+	return c.active
+}
+
+func (c MockProfileConfiguration) ProfileNames() []string {
+	return c.profiles
+}
+
+func (c *MockProfileConfiguration) UseProfile(name string) error {
+	for _, p := range c.profiles {
+		if p == name {
+			c.active = name
+			return nil
+		}
+	}
+	return fmt.Errorf("profile %s does not exist", name)
+}
+
+func (c *MockProfileConfiguration) AddProfile(name string) error {
+	c.profiles = append(c.profiles, name)
+	return nil
+}
+
+func (c *MockProfileConfiguration) RemoveProfile(name string) error {
+	return fmt.Errorf("not supported")
+}
+
+var _ ports.ProfileConfiguration = (*MockProfileConfiguration)(nil)
+
+func TestProfileApi_Use(t *testing.T) {
+	tests := []struct {
+		name           string
+		profile        string
+		wantErr        bool
+		wantActive     string
+		wantSaveCalled bool
+	}{
+		{name: "existing profile", profile: "internal", wantErr: false, wantActive: "internal", wantSaveCalled: true},
+		{name: "missing profile", profile: "missing", wantErr: true, wantActive: "default", wantSaveCalled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockProfileConfiguration{profiles: []string{"default", "internal"}, active: "default"}
+			p := ProfileApi{Configuration: &mc}
+			if err := p.Use(tt.profile); (err != nil) != tt.wantErr {
+				t.Errorf("ProfileApi.Use() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, active := p.List(); active != tt.wantActive {
+				t.Errorf("ProfileApi.Use() active = %v, want %v", active, tt.wantActive)
+			}
+			if mc.saveCalled != tt.wantSaveCalled {
+				t.Errorf("ProfileApi.Use() saveCalled = %v, wantSaveCalled %v", mc.saveCalled, tt.wantSaveCalled)
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/seboste/sapper/ports"
 )
@@ -26,6 +32,29 @@ func findRemote(remotes []ports.Remote, name string) (index int, remote ports.Re
 	return
 }
 
+func remoteDir(r ports.Remote, remotesDir string) string {
+	if r.Kind == ports.GitRemote {
+		return ports.CloneDir(remotesDir, r.Src)
+	}
+	return r.Src
+}
+
+// checkUserRemote returns an error if the remote is part of the project configuration, which cannot be changed by sapper
+func (r RemoteApi) checkUserRemote(name string) error {
+	if _, _, ok := findRemote(r.Configuration.ProjectRemotes(), name); ok {
+		return fmt.Errorf("remote %s is configured by the project. Change the project's .sapper.yaml instead", name)
+	}
+	return nil
+}
+
+// checkUserPosition returns an error if a remote at the position would precede the remotes of the project, which always come first
+func (r RemoteApi) checkUserPosition(position int) error {
+	if count := len(r.Configuration.ProjectRemotes()); position >= 0 && position < count {
+		return fmt.Errorf("invalid position %v. The remotes of the project come first, i.e. the position must be at least %v", position, count)
+	}
+	return nil
+}
+
 func inferKind(src string) (kind ports.RemoteKind, err error) {
 	if src[len(src)-4:] == ".git" {
 		return ports.GitRemote, nil
@@ -52,6 +81,9 @@ func (r RemoteApi) Add(name string, src string, position int) error {
 	if _, _, ok := findRemote(remotes, name); ok {
 		return fmt.Errorf("remote with name %s does already exist", name)
 	}
+	if err := r.checkUserPosition(position); err != nil {
+		return err
+	}
 
 	kind, err := inferKind(src)
 	if err != nil {
@@ -75,10 +107,14 @@ func (r RemoteApi) Add(name string, src string, position int) error {
 }
 
 func (r RemoteApi) Remove(name string) error {
+	if err := r.checkUserRemote(name); err != nil {
+		return err
+	}
 	remotes := r.Configuration.Remotes()
 	if i, _, ok := findRemote(remotes, name); ok {
 		remotes = append(remotes[:i], remotes[i+1:]...)
 		r.Configuration.UpdateRemotes(remotes)
+		r.Configuration.UpdatePins(renamePinnedRemote(r.Configuration.Pins(), name, ""))
 
 		if err := r.Configuration.Save(); err != nil {
 			return err
@@ -87,7 +123,102 @@ func (r RemoteApi) Remove(name string) error {
 	}
 	return fmt.Errorf("remote %s does not exist", name)
 }
-func (r RemoteApi) Update(name string) error {
+
+// renamePinnedRemote changes the remote of all pins referring to the remote name. Pins are removed if newName is empty.
+func renamePinnedRemote(pins []ports.BrickPin, name string, newName string) []ports.BrickPin {
+	result := []ports.BrickPin{}
+	for _, p := range pins {
+		if p.Remote == name {
+			if newName == "" {
+				continue
+			}
+			p.Remote = newName
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// Rename renames a remote. The clone of a git remote is kept as it is identified by the url.
+func (r RemoteApi) Rename(name string, newName string) error {
+	if err := r.checkUserRemote(name); err != nil {
+		return err
+	}
+	remotes := r.Configuration.Remotes()
+	i, remote, ok := findRemote(remotes, name)
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", name)
+	}
+	if _, _, ok := findRemote(remotes, newName); ok {
+		return fmt.Errorf("remote with name %s does already exist", newName)
+	}
+
+	renamed := remote
+	renamed.Name = newName
+	remotes[i] = renamed
+	r.Configuration.UpdateRemotes(remotes)
+	r.Configuration.UpdatePins(renamePinnedRemote(r.Configuration.Pins(), name, newName))
+	return r.Configuration.Save()
+}
+
+// Move changes the priority of a remote by moving it to the given position
+func (r RemoteApi) Move(name string, position int) error {
+	remotes := r.Configuration.Remotes()
+	i, remote, ok := findRemote(remotes, name)
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", name)
+	}
+	if position < 0 || position >= len(remotes) {
+		return fmt.Errorf("invalid position %v. Must be between 0 and %v", position, len(remotes)-1)
+	}
+	if err := r.checkUserRemote(name); err != nil {
+		return err
+	}
+	if err := r.checkUserPosition(position); err != nil {
+		return err
+	}
+
+	others := []ports.Remote{}
+	others = append(others, remotes[:i]...)
+	others = append(others, remotes[i+1:]...)
+
+	moved := []ports.Remote{}
+	moved = append(moved, others[:position]...)
+	moved = append(moved, remote)
+	moved = append(moved, others[position:]...)
+
+	r.Configuration.UpdateRemotes(moved)
+	return r.Configuration.Save()
+}
+
+// SetUrl changes the source of a remote. A git remote is cloned from the new source. The clone of the previous source is kept as
+// it may be shared with other remotes of the same url (e.g. in other profiles).
+func (r RemoteApi) SetUrl(name string, src string) error {
+	if err := r.checkUserRemote(name); err != nil {
+		return err
+	}
+	remotes := r.Configuration.Remotes()
+	i, _, ok := findRemote(remotes, name)
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", name)
+	}
+
+	kind, err := inferKind(src)
+	if err != nil {
+		return err
+	}
+	changed := ports.Remote{Name: name, Src: src, Kind: kind}
+
+	if _, err := r.BrickDBFactory.MakeBrickDB(changed, r.Configuration.DefaultRemotesDir()); err != nil {
+		return err
+	}
+
+	remotes[i] = changed
+	r.Configuration.UpdateRemotes(remotes)
+	return r.Configuration.Save()
+}
+
+func (r RemoteApi) Update(name string, writer io.Writer) error {
 	_, remote, ok := findRemote(r.Configuration.Remotes(), name)
 	if !ok {
 		return fmt.Errorf("remote %s does not exist", name)
@@ -98,8 +229,103 @@ func (r RemoteApi) Update(name string) error {
 		return err
 	}
 
-	return brickDB.Update()
+	return brickDB.Update(writer)
+}
+
+// maxParallelUpdates limits the number of remotes that are updated concurrently
+const maxParallelUpdates = 4
+
+type remoteUpdateResult struct {
+	remote   ports.Remote
+	skipped  bool
+	err      error
+	duration time.Duration
+}
+
+// updateRemotes updates the git remotes for which needsUpdate returns true concurrently. The results are returned in the order of the remotes.
+func (r RemoteApi) updateRemotes(remotes []ports.Remote, needsUpdate func(brickDB ports.BrickDB) bool) []remoteUpdateResult {
+	results := make([]remoteUpdateResult, len(remotes))
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < maxParallelUpdates && w < len(remotes); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				result := remoteUpdateResult{remote: remotes[i], skipped: true}
+				start := time.Now()
+				if remotes[i].Kind == ports.GitRemote {
+					brickDB, err := r.BrickDBFactory.MakeBrickDB(remotes[i], r.Configuration.DefaultRemotesDir())
+					if err != nil {
+						result.skipped = false
+						result.err = err
+					} else if needsUpdate(brickDB) {
+						//the output is only reported in case of an error so that the output of the remotes is not interleaved
+						var output bytes.Buffer
+						result.skipped = false
+						if result.err = brickDB.Update(&output); result.err != nil && output.Len() > 0 {
+							result.err = fmt.Errorf("%v\n%s", result.err, strings.TrimSpace(output.String()))
+						}
+					}
+				}
+				result.duration = time.Since(start)
+				results[i] = result
+			}
+		}()
+	}
+	for i := range remotes {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
+
+func (r RemoteApi) UpdateAll(writer io.Writer) error {
+	remotes := r.Configuration.Remotes()
+	results := r.updateRemotes(remotes, func(ports.BrickDB) bool { return true })
+
+	errorCount := 0
+	for _, result := range results {
+		if result.skipped {
+			fmt.Fprintf(writer, "%s: skipped (no git remote)\n", result.remote.Name)
+		} else if result.err != nil {
+			errorCount++
+			fmt.Fprintf(writer, "%s: failed (%v)\n", result.remote.Name, result.err)
+		} else {
+			fmt.Fprintf(writer, "%s: updated (%v)\n", result.remote.Name, result.duration.Round(time.Millisecond))
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%v of %v remotes failed to update", errorCount, len(remotes))
+	}
+	return nil
+}
+
+// UpdateStale updates all git remotes that have not been updated within the configured TTL. Failures are only reported as warnings (e.g. when working offline).
+func (r RemoteApi) UpdateStale(writer io.Writer) error {
+	ttl := r.Configuration.RemoteUpdateTTL()
+	if ttl <= 0 {
+		return nil
+	}
+
+	results := r.updateRemotes(r.Configuration.Remotes(), func(brickDB ports.BrickDB) bool {
+		return time.Since(brickDB.LastUpdate()) > ttl
+	})
+	for _, result := range results {
+		if result.skipped {
+			continue
+		}
+		if result.err != nil {
+			fmt.Fprintf(writer, "warning: unable to update remote %s: %v\n", result.remote.Name, result.err)
+		} else {
+			fmt.Fprintf(writer, "updated remote %s as it was older than %v\n", result.remote.Name, ttl)
+		}
+	}
+	return nil
 }
+
 func (r RemoteApi) Upgrade(name string) error {
 	_, remote, ok := findRemote(r.Configuration.Remotes(), name)
 	if !ok {
@@ -112,13 +338,10 @@ func (r RemoteApi) Upgrade(name string) error {
 	}
 
 	errorCount := 0
-	allBricks := []ports.Brick{}
-	for _, k := range ports.BrickKinds {
-		allBricks = append(allBricks, brickDB.Bricks(k)...)
-	}
+	bricks := allBricks(brickDB)
 
-	for i, brick := range allBricks {
-		fmt.Printf("upgrading %s (%v/%v)...\n", brick.Id, i+1, len(allBricks))
+	for i, brick := range bricks {
+		fmt.Printf("upgrading %s (%v/%v)...\n", brick.Id, i+1, len(bricks))
 		err := r.BrickUpgrader.UpgradeInDB(brick.Id, brickDB)
 		if err != nil {
 			errorCount++
@@ -130,11 +353,106 @@ func (r RemoteApi) Upgrade(name string) error {
 	}
 
 	if errorCount > 0 {
-		return fmt.Errorf("%v of %v bricks failed to upgrade.", errorCount, len(allBricks))
+		return fmt.Errorf("%v of %v bricks failed to upgrade.", errorCount, len(bricks))
+	}
+
+	return nil
+}
+
+func (r RemoteApi) Verify(name string, writer io.Writer) error {
+	remotes := r.Configuration.Remotes()
+	i, remote, ok := findRemote(remotes, name)
+	if !ok {
+		return fmt.Errorf("remote %s does not exist", name)
+	}
+
+	db, err := r.BrickDBFactory.MakeBrickDB(remote, r.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
 	}
 
+	//dependencies may also be provided by other remotes, but the verified remote takes precedence
+	dependencyRemotes := append([]ports.Remote{remote}, remotes[:i]...)
+	dependencyRemotes = append(dependencyRemotes, remotes[i+1:]...)
+	dependencyDb, err := r.BrickDBFactory.MakeAggregatedBrickDB(dependencyRemotes, r.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	ids, issues := verifyBricks(db, dependencyDb)
+	for _, id := range ids {
+		bi := issues[id]
+		if len(bi.errors) == 0 && len(bi.warnings) == 0 {
+			fmt.Fprintf(writer, "%s: ok\n", id)
+			continue
+		}
+		fmt.Fprintf(writer, "%s:\n", id)
+		for _, e := range bi.errors {
+			fmt.Fprintf(writer, "  error: %s\n", e)
+		}
+		for _, w := range bi.warnings {
+			fmt.Fprintf(writer, "  warning: %s\n", w)
+		}
+		errorCount += len(bi.errors)
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("found %v errors in remote %s", errorCount, name)
+	}
 	return nil
 }
+
+// Pin makes sure that bricks matching the pattern (a brick id or a glob such as 'cpp-*') are only taken from the given remote.
+// An existing pin with the same pattern is replaced.
+func (r RemoteApi) Pin(pattern string, name string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid brick pattern %s: %v", pattern, err)
+	}
+	if _, _, ok := findRemote(r.Configuration.Remotes(), name); !ok {
+		return fmt.Errorf("remote %s does not exist", name)
+	}
+
+	pins := []ports.BrickPin{}
+	replaced := false
+	for _, p := range r.Configuration.Pins() {
+		if p.Brick == pattern {
+			p.Remote = name
+			replaced = true
+		}
+		pins = append(pins, p)
+	}
+	if !replaced {
+		pins = append(pins, ports.BrickPin{Brick: pattern, Remote: name})
+	}
+
+	r.Configuration.UpdatePins(pins)
+	return r.Configuration.Save()
+}
+
+// Unpin removes the pin with the given pattern
+func (r RemoteApi) Unpin(pattern string) error {
+	pins := []ports.BrickPin{}
+	found := false
+	for _, p := range r.Configuration.Pins() {
+		if p.Brick == pattern {
+			found = true
+			continue
+		}
+		pins = append(pins, p)
+	}
+	if !found {
+		return fmt.Errorf("brick %s is not pinned", pattern)
+	}
+
+	r.Configuration.UpdatePins(pins)
+	return r.Configuration.Save()
+}
+
+func (r RemoteApi) Pins() []ports.BrickPin {
+	return r.Configuration.Pins()
+}
+
 func (r RemoteApi) List() []ports.Remote {
 	return r.Configuration.Remotes()
 }
@@ -1,11 +1,15 @@
 package core
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/seboste/sapper/ports"
 )
@@ -110,8 +114,13 @@ func Test_findRemote(t *testing.T) {
 }
 
 type MockConfiguration struct {
-	saveCalled bool
-	remotes    []ports.Remote
+	saveCalled      bool
+	remotesDir      string //os.TempDir() if empty
+	remotes         []ports.Remote
+	projectRemotes  []ports.Remote //the first remotes of remotes
+	pins            []ports.BrickPin
+	parameters      map[string]string
+	remoteUpdateTTL time.Duration
 }
 
 func (c *MockConfiguration) Save() error {
@@ -120,6 +129,9 @@ func (c *MockConfiguration) Save() error {
 }
 
 func (c MockConfiguration) DefaultRemotesDir() string {
+	if c.remotesDir != "" {
+		return c.remotesDir
+	}
 	return os.TempDir()
 }
 
@@ -130,6 +142,30 @@ func (c *MockConfiguration) UpdateRemotes(remotes []ports.Remote) {
 	c.remotes = remotes
 }
 
+func (c MockConfiguration) ProjectRemotes() []ports.Remote {
+	return c.projectRemotes
+}
+
+func (c MockConfiguration) Pins() []ports.BrickPin {
+	return c.pins
+}
+
+func (c *MockConfiguration) UpdatePins(pins []ports.BrickPin) {
+	c.pins = pins
+}
+
+func (c MockConfiguration) Parameters() map[string]string {
+	return c.parameters
+}
+
+func (c *MockConfiguration) UpdateParameters(parameters map[string]string) {
+	c.parameters = parameters
+}
+
+func (c MockConfiguration) RemoteUpdateTTL() time.Duration {
+	return c.remoteUpdateTTL
+}
+
 var _ ports.Configuration = (*MockConfiguration)(nil)
 
 type MockBrickDBFactory struct {
@@ -242,6 +278,296 @@ func TestRemoteApi_Remove(t *testing.T) {
 	}
 }
 
+func TestRemoteApi_Rename(t *testing.T) {
+	gitName := "git"
+
+	tests := []struct {
+		name           string
+		oldName        string
+		newName        string
+		wantErr        bool
+		wantSaveCalled bool
+		wantRemotes    []ports.Remote
+	}{
+		{name: "file system remote", oldName: "a", newName: "c", wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "c"}, {Name: gitName, Kind: ports.GitRemote}}},
+		{name: "git remote", oldName: gitName, newName: gitName + "-renamed", wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "a"}, {Name: gitName + "-renamed", Kind: ports.GitRemote}}},
+		{name: "absent", oldName: "missing", newName: "c", wantErr: true, wantSaveCalled: false, wantRemotes: []ports.Remote{{Name: "a"}, {Name: gitName, Kind: ports.GitRemote}}},
+		{name: "name already taken", oldName: "a", newName: gitName, wantErr: true, wantSaveCalled: false, wantRemotes: []ports.Remote{{Name: "a"}, {Name: gitName, Kind: ports.GitRemote}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockConfiguration{remotes: []ports.Remote{{Name: "a"}, {Name: gitName, Kind: ports.GitRemote}}}
+			r := RemoteApi{Configuration: &mc, BrickDBFactory: MockBrickDBFactory{}}
+			if err := r.Rename(tt.oldName, tt.newName); (err != nil) != tt.wantErr {
+				t.Errorf("RemoteApi.Rename() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotRemotes := r.Configuration.Remotes(); !reflect.DeepEqual(gotRemotes, tt.wantRemotes) {
+				t.Errorf("RemoteApi.Rename() remotes = %v, wantRemotes %v", gotRemotes, tt.wantRemotes)
+			}
+			if mc.saveCalled != tt.wantSaveCalled {
+				t.Errorf("RemoteApi.Rename() saveCalled = %v, wantSaveCalled %v", mc.saveCalled, tt.wantSaveCalled)
+			}
+		})
+	}
+}
+
+func TestRemoteApi_RemoveAndRenamePins(t *testing.T) {
+	pins := []ports.BrickPin{{Brick: "x", Remote: "a"}, {Brick: "y-*", Remote: "b"}}
+
+	mc := MockConfiguration{remotes: []ports.Remote{{Name: "a"}, {Name: "b"}}, pins: pins}
+	r := RemoteApi{Configuration: &mc, BrickDBFactory: MockBrickDBFactory{}}
+	if err := r.Rename("a", "c"); err != nil {
+		t.Fatalf("RemoteApi.Rename() error = %v", err)
+	}
+	if want := []ports.BrickPin{{Brick: "x", Remote: "c"}, {Brick: "y-*", Remote: "b"}}; !reflect.DeepEqual(mc.pins, want) {
+		t.Errorf("RemoteApi.Rename() pins = %v, want %v", mc.pins, want)
+	}
+	if err := r.Remove("b"); err != nil {
+		t.Fatalf("RemoteApi.Remove() error = %v", err)
+	}
+	if want := []ports.BrickPin{{Brick: "x", Remote: "c"}}; !reflect.DeepEqual(mc.pins, want) {
+		t.Errorf("RemoteApi.Remove() pins = %v, want %v", mc.pins, want)
+	}
+}
+
+func TestRemoteApi_ProjectRemotes(t *testing.T) {
+	project := []ports.Remote{{Name: "project", Kind: ports.GitRemote, Src: "project.git"}}
+	remotes := append(append([]ports.Remote{}, project...), ports.Remote{Name: "user", Kind: ports.GitRemote, Src: "user.git"})
+	tests := []struct {
+		name    string
+		change  func(r RemoteApi) error
+		wantErr bool
+	}{
+		{name: "remove", change: func(r RemoteApi) error { return r.Remove("project") }, wantErr: true},
+		{name: "rename", change: func(r RemoteApi) error { return r.Rename("project", "renamed") }, wantErr: true},
+		{name: "set url", change: func(r RemoteApi) error { return r.SetUrl("project", "other.git") }, wantErr: true},
+		{name: "move", change: func(r RemoteApi) error { return r.Move("project", 1) }, wantErr: true},
+		{name: "move before project", change: func(r RemoteApi) error { return r.Move("user", 0) }, wantErr: true},
+		{name: "add before project", change: func(r RemoteApi) error { return r.Add("new", "new.git", 0) }, wantErr: true},
+		{name: "add after project", change: func(r RemoteApi) error { return r.Add("new", "new.git", 1) }},
+		{name: "remove user remote", change: func(r RemoteApi) error { return r.Remove("user") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockConfiguration{remotes: append([]ports.Remote{}, remotes...), projectRemotes: project}
+			r := RemoteApi{Configuration: &mc, BrickDBFactory: MockBrickDBFactory{}}
+			if err := tt.change(r); (err != nil) != tt.wantErr {
+				t.Errorf("RemoteApi error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (mc.saveCalled || !reflect.DeepEqual(mc.remotes, remotes)) {
+				t.Errorf("RemoteApi changed the remotes to %v", mc.remotes)
+			}
+		})
+	}
+}
+
+func TestRemoteApi_Pin(t *testing.T) {
+	initialPins := []ports.BrickPin{{Brick: "x", Remote: "a"}}
+	tests := []struct {
+		name           string
+		pattern        string
+		remote         string
+		wantErr        bool
+		wantSaveCalled bool
+		wantPins       []ports.BrickPin
+	}{
+		{name: "new pin", pattern: "y-*", remote: "b", wantSaveCalled: true, wantPins: []ports.BrickPin{{Brick: "x", Remote: "a"}, {Brick: "y-*", Remote: "b"}}},
+		{name: "replace pin", pattern: "x", remote: "b", wantSaveCalled: true, wantPins: []ports.BrickPin{{Brick: "x", Remote: "b"}}},
+		{name: "unknown remote", pattern: "y", remote: "missing", wantErr: true, wantPins: initialPins},
+		{name: "invalid pattern", pattern: "[y", remote: "b", wantErr: true, wantPins: initialPins},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockConfiguration{remotes: []ports.Remote{{Name: "a"}, {Name: "b"}}, pins: initialPins}
+			r := RemoteApi{Configuration: &mc, BrickDBFactory: MockBrickDBFactory{}}
+			if err := r.Pin(tt.pattern, tt.remote); (err != nil) != tt.wantErr {
+				t.Errorf("RemoteApi.Pin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(r.Pins(), tt.wantPins) {
+				t.Errorf("RemoteApi.Pin() pins = %v, wantPins %v", r.Pins(), tt.wantPins)
+			}
+			if mc.saveCalled != tt.wantSaveCalled {
+				t.Errorf("RemoteApi.Pin() saveCalled = %v, wantSaveCalled %v", mc.saveCalled, tt.wantSaveCalled)
+			}
+		})
+	}
+}
+
+func TestRemoteApi_Unpin(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		wantErr  bool
+		wantPins []ports.BrickPin
+	}{
+		{name: "pinned", pattern: "x", wantPins: []ports.BrickPin{{Brick: "y-*", Remote: "b"}}},
+		{name: "not pinned", pattern: "y", wantErr: true, wantPins: []ports.BrickPin{{Brick: "x", Remote: "a"}, {Brick: "y-*", Remote: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockConfiguration{pins: []ports.BrickPin{{Brick: "x", Remote: "a"}, {Brick: "y-*", Remote: "b"}}}
+			r := RemoteApi{Configuration: &mc}
+			if err := r.Unpin(tt.pattern); (err != nil) != tt.wantErr {
+				t.Errorf("RemoteApi.Unpin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(mc.pins, tt.wantPins) {
+				t.Errorf("RemoteApi.Unpin() pins = %v, wantPins %v", mc.pins, tt.wantPins)
+			}
+		})
+	}
+}
+
+func TestRemoteApi_Move(t *testing.T) {
+	abc := []ports.Remote{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	tests := []struct {
+		name           string
+		remote         string
+		position       int
+		wantErr        bool
+		wantSaveCalled bool
+		wantRemotes    []ports.Remote
+	}{
+		{name: "to front", remote: "c", position: 0, wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "c"}, {Name: "a"}, {Name: "b"}}},
+		{name: "to back", remote: "a", position: 2, wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "b"}, {Name: "c"}, {Name: "a"}}},
+		{name: "to middle", remote: "a", position: 1, wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "b"}, {Name: "a"}, {Name: "c"}}},
+		{name: "same position", remote: "b", position: 1, wantErr: false, wantSaveCalled: true, wantRemotes: abc},
+		{name: "invalid position", remote: "b", position: 3, wantErr: true, wantSaveCalled: false, wantRemotes: abc},
+		{name: "absent", remote: "missing", position: 0, wantErr: true, wantSaveCalled: false, wantRemotes: abc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockConfiguration{remotes: append([]ports.Remote{}, abc...)}
+			r := RemoteApi{Configuration: &mc, BrickDBFactory: MockBrickDBFactory{}}
+			if err := r.Move(tt.remote, tt.position); (err != nil) != tt.wantErr {
+				t.Errorf("RemoteApi.Move() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotRemotes := r.Configuration.Remotes(); !reflect.DeepEqual(gotRemotes, tt.wantRemotes) {
+				t.Errorf("RemoteApi.Move() remotes = %v, wantRemotes %v", gotRemotes, tt.wantRemotes)
+			}
+			if mc.saveCalled != tt.wantSaveCalled {
+				t.Errorf("RemoteApi.Move() saveCalled = %v, wantSaveCalled %v", mc.saveCalled, tt.wantSaveCalled)
+			}
+		})
+	}
+}
+
+func TestRemoteApi_SetUrl(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "setUrlTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	tests := []struct {
+		name           string
+		remote         string
+		src            string
+		wantErr        bool
+		wantSaveCalled bool
+		wantRemotes    []ports.Remote
+	}{
+		{name: "git url", remote: "setUrlTestRemote", src: "otherurl.git", wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "setUrlTestRemote", Kind: ports.GitRemote, Src: "otherurl.git"}}},
+		{name: "file system path", remote: "setUrlTestRemote", src: tempDir, wantErr: false, wantSaveCalled: true, wantRemotes: []ports.Remote{{Name: "setUrlTestRemote", Kind: ports.FilesystemRemote, Src: tempDir}}},
+		{name: "invalid src", remote: "setUrlTestRemote", src: "someurl.invalid", wantErr: true, wantSaveCalled: false, wantRemotes: []ports.Remote{{Name: "setUrlTestRemote", Kind: ports.GitRemote, Src: "someurl.git"}}},
+		{name: "absent", remote: "missing", src: "otherurl.git", wantErr: true, wantSaveCalled: false, wantRemotes: []ports.Remote{{Name: "setUrlTestRemote", Kind: ports.GitRemote, Src: "someurl.git"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := MockConfiguration{remotes: []ports.Remote{{Name: "setUrlTestRemote", Kind: ports.GitRemote, Src: "someurl.git"}}, remotesDir: tempDir}
+			r := RemoteApi{Configuration: &mc, BrickDBFactory: MockBrickDBFactory{}}
+			if err := r.SetUrl(tt.remote, tt.src); (err != nil) != tt.wantErr {
+				t.Errorf("RemoteApi.SetUrl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotRemotes := r.Configuration.Remotes(); !reflect.DeepEqual(gotRemotes, tt.wantRemotes) {
+				t.Errorf("RemoteApi.SetUrl() remotes = %v, wantRemotes %v", gotRemotes, tt.wantRemotes)
+			}
+			if mc.saveCalled != tt.wantSaveCalled {
+				t.Errorf("RemoteApi.SetUrl() saveCalled = %v, wantSaveCalled %v", mc.saveCalled, tt.wantSaveCalled)
+			}
+		})
+	}
+}
+
+func TestRemoteApi_SetUrl_SharedClone(t *testing.T) {
+	//cloning writes the url into the clone
+	factory := FakeBrickDBFactory{makeBrickDB: func(r ports.Remote, remotesDir string) (ports.BrickDB, error) {
+		dir := ports.CloneDir(remotesDir, r.Src)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			os.MkdirAll(dir, 0777)
+			ioutil.WriteFile(filepath.Join(dir, "url"), []byte(r.Src), 0666)
+		}
+		return FakeBrickDB{}, nil
+	}}
+
+	remotesDir, _ := ioutil.TempDir("", "setUrlTest*")
+	defer os.RemoveAll(remotesDir) // clean up
+	remote := ports.Remote{Name: "git", Kind: ports.GitRemote, Src: "someurl.git"}
+	factory.MakeBrickDB(remote, remotesDir)
+
+	mc := MockConfiguration{remotes: []ports.Remote{remote}, remotesDir: remotesDir}
+	r := RemoteApi{Configuration: &mc, BrickDBFactory: factory}
+	if err := r.SetUrl("git", "otherurl.git"); err != nil {
+		t.Fatalf("RemoteApi.SetUrl() error = %v", err)
+	}
+	for _, url := range []string{"someurl.git", "otherurl.git"} { //the previous clone may be used by another profile
+		if got, _ := ioutil.ReadFile(filepath.Join(ports.CloneDir(remotesDir, url), "url")); string(got) != url {
+			t.Errorf("RemoteApi.SetUrl() clone of %s = %q, want %q", url, string(got), url)
+		}
+	}
+}
+
+func TestRemoteApi_UpdateAll(t *testing.T) {
+	remotes := []ports.Remote{{Name: "fs", Kind: ports.FilesystemRemote}, {Name: "unreachable", Kind: ports.GitRemote}}
+	updateCalled := map[string]*bool{"fs": new(bool), "unreachable": new(bool)}
+	factory := FakeBrickDBFactory{remotes: map[string]ports.BrickDB{"fs": &TestBrickDB{updateCalled: updateCalled["fs"]}}}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("git%d", i)
+		remotes = append(remotes, ports.Remote{Name: name, Kind: ports.GitRemote})
+		updateCalled[name] = new(bool)
+		factory.remotes[name] = &TestBrickDB{updateCalled: updateCalled[name]}
+	}
+
+	r := RemoteApi{Configuration: &MockConfiguration{remotes: remotes}, BrickDBFactory: factory}
+	var output bytes.Buffer
+	if err := r.UpdateAll(&output); err == nil {
+		t.Errorf("RemoteApi.UpdateAll() error = nil, want an error for the unreachable remote")
+	}
+	for name, called := range updateCalled {
+		if want := strings.HasPrefix(name, "git"); *called != want {
+			t.Errorf("RemoteApi.UpdateAll() updateCalled[%s] = %v, want %v", name, *called, want)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != len(remotes) || !strings.HasPrefix(lines[0], "fs: skipped") || !strings.HasPrefix(lines[1], "unreachable: failed") || !strings.HasPrefix(lines[2], "git0: updated") {
+		t.Errorf("RemoteApi.UpdateAll() report = %v", output.String())
+	}
+}
+
+func TestRemoteApi_UpdateStale(t *testing.T) {
+	tests := []struct {
+		name             string
+		ttl              time.Duration
+		lastUpdate       time.Time
+		wantUpdateCalled bool
+	}{
+		{name: "disabled", ttl: 0, lastUpdate: time.Time{}, wantUpdateCalled: false},
+		{name: "stale", ttl: time.Hour, lastUpdate: time.Now().Add(-2 * time.Hour), wantUpdateCalled: true},
+		{name: "fresh", ttl: time.Hour, lastUpdate: time.Now().Add(-time.Minute), wantUpdateCalled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUpdateCalled := false
+			r := RemoteApi{
+				Configuration:  &MockConfiguration{remotes: []ports.Remote{{Name: "a", Kind: ports.GitRemote}}, remoteUpdateTTL: tt.ttl},
+				BrickDBFactory: FakeBrickDBFactory{remotes: map[string]ports.BrickDB{"a": &TestBrickDB{updateCalled: &gotUpdateCalled, lastUpdate: tt.lastUpdate}}},
+			}
+			if err := r.UpdateStale(&bytes.Buffer{}); err != nil {
+				t.Errorf("RemoteApi.UpdateStale() error = %v", err)
+			}
+			if gotUpdateCalled != tt.wantUpdateCalled {
+				t.Errorf("RemoteApi.UpdateStale() updateCalled = %v, wantUpdateCalled %v", gotUpdateCalled, tt.wantUpdateCalled)
+			}
+		})
+	}
+}
+
 func TestRemoteApi_Update(t *testing.T) {
 	type fields struct {
 		InitialRemotes []ports.Remote
@@ -271,9 +597,17 @@ func TestRemoteApi_Update(t *testing.T) {
 				BrickDBFactory: &mbdf,
 			}
 
-			if err := r.Update(tt.args.name); (err != nil) != tt.wantErr {
+			var output bytes.Buffer
+			if err := r.Update(tt.args.name, &output); (err != nil) != tt.wantErr {
 				t.Errorf("RemoteApi.Update() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			wantOutput := ""
+			if tt.wantUpdateCalled {
+				wantOutput = "pulled\n"
+			}
+			if output.String() != wantOutput {
+				t.Errorf("RemoteApi.Update() output = %q, want %q", output.String(), wantOutput)
+			}
 
 			if gotUpdateCalled != tt.wantUpdateCalled {
 				t.Errorf("RemoteApi.Update() updateCalled = %v, wantUpdateCalled %v", gotUpdateCalled, tt.wantUpdateCalled)
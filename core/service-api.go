@@ -16,6 +16,23 @@ import (
 	"github.com/seboste/sapper/utils"
 )
 
+var CyclicBrickDependency = errors.New("cyclic brick dependency")
+
+// serviceMetadataDir is the folder within a service that contains the files maintained by sapper (e.g. vendored bricks or secrets)
+const serviceMetadataDir = ".sapper"
+
+// vendoredBricksDir is the folder within a service that contains the bricks vendored by 'sapper service vendor'
+var vendoredBricksDir = filepath.Join(serviceMetadataDir, "bricks")
+
+// serviceRemotes returns the remotes to be used for operations on a service. The bricks vendored into the service take precedence over all other remotes.
+func serviceRemotes(servicePath string, remotes []ports.Remote) []ports.Remote {
+	vendorDir := filepath.Join(servicePath, vendoredBricksDir)
+	if info, err := os.Stat(vendorDir); err != nil || !info.IsDir() {
+		return remotes
+	}
+	return append([]ports.Remote{{Name: "vendored", Kind: ports.FilesystemRemote, Src: vendorDir}}, remotes...)
+}
+
 type ServiceApi struct {
 	Configuration      ports.Configuration
 	BrickDBFactory     ports.BrickDBFactory
@@ -28,28 +45,105 @@ type ServiceApi struct {
 	Stderr             io.Writer
 }
 
-func ResolveParameters(bp []ports.BrickParameters, pr ports.ParameterResolver) (map[string]string, error) {
-	parameters := make(map[string]string)
+// resolveParameters resolves the parameters of a brick into parameters and appends the ones that cannot be resolved to missing
+func resolveParameters(brickId string, bp []ports.BrickParameters, pr ports.ParameterResolver, parameters map[string]string, missing *ports.MissingParametersError) {
 	for _, p := range bp {
-		value := pr.Resolve(p.Name, p.Default)
-		if value == "" {
-			return nil, fmt.Errorf("unable to resolve value for parameter %s", p.Name)
+		if value := pr.Resolve(p.Name, p.Default); value != "" {
+			parameters[p.Name] = value
+			continue
+		}
+		found := false
+		for i := range missing.Parameters {
+			if missing.Parameters[i].Name == p.Name {
+				if brickId != "" {
+					missing.Parameters[i].Bricks = append(missing.Parameters[i].Bricks, brickId)
+				}
+				if missing.Parameters[i].Description == "" {
+					missing.Parameters[i].Description = p.Description
+				}
+				found = true
+			}
+		}
+		if !found {
+			mp := ports.MissingParameter{Name: p.Name, Description: p.Description}
+			if brickId != "" {
+				mp.Bricks = []string{brickId}
+			}
+			missing.Parameters = append(missing.Parameters, mp)
+		}
+	}
+}
+
+func ResolveParameters(bp []ports.BrickParameters, pr ports.ParameterResolver) (map[string]string, error) {
+	return ResolveParameterSlice([]ports.Brick{{Parameters: bp}}, pr)
+}
+
+// validateParameters checks that the parameter resolver does not provide parameters that are not declared by the bricks
+func validateParameters(bricks []ports.Brick, declared map[string]string, pr ports.ParameterResolver) error {
+	v, ok := pr.(ports.ParameterValidator)
+	if !ok {
+		return nil
+	}
+	parameters := []ports.BrickParameters{}
+	for _, brick := range bricks {
+		parameters = append(parameters, brick.Parameters...)
+	}
+	for name, value := range declared {
+		parameters = append(parameters, ports.BrickParameters{Name: name, Default: value})
+	}
+	return v.Validate(parameters)
+}
+
+// parameterRequests lists the parameters of all bricks along with their values. Parameters declared by several bricks are listed once.
+func parameterRequests(bricks []ports.Brick, values map[string]string) []ports.ParameterRequest {
+	requests := []ports.ParameterRequest{}
+	listed := map[string]bool{}
+	for _, brick := range bricks {
+		for _, p := range brick.Parameters {
+			if !listed[p.Name] {
+				requests = append(requests, ports.ParameterRequest{Brick: brick.Id, Parameter: p, Value: values[p.Name]})
+				listed[p.Name] = true
+			}
 		}
-		parameters[p.Name] = value
 	}
-	return parameters, nil
+	return requests
 }
 
+// ResolveParameterSlice resolves the parameters of all bricks. Parameters that cannot be resolved are requested at once if the
+// resolver is a ports.ParameterPrompter. If some of them still cannot be resolved, a MissingParametersError lists all of them.
 func ResolveParameterSlice(bricks []ports.Brick, pr ports.ParameterResolver) (map[string]string, error) {
 	combinedParameters := map[string]string{}
+	missing := ports.MissingParametersError{}
 	for _, brick := range bricks {
-		p, err := ResolveParameters(brick.Parameters, pr)
+		resolveParameters(brick.Id, brick.Parameters, pr, combinedParameters, &missing)
+	}
+
+	if prompter, ok := pr.(ports.ParameterPrompter); ok && len(missing.Parameters) > 0 {
+		values, err := prompter.Prompt(parameterRequests(bricks, combinedParameters))
 		if err != nil {
 			return nil, err
 		}
-		for k, v := range p {
+		for k, v := range values {
 			combinedParameters[k] = v
 		}
+		stillMissing := []ports.MissingParameter{}
+		for _, mp := range missing.Parameters {
+			if combinedParameters[mp.Name] == "" {
+				stillMissing = append(stillMissing, mp)
+			}
+		}
+		missing.Parameters = stillMissing
+	}
+	if len(missing.Parameters) > 0 {
+		return nil, missing
+	}
+
+	for _, brick := range bricks {
+		for _, p := range brick.Parameters {
+			if err := p.Validate(combinedParameters[p.Name]); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return combinedParameters, nil
 }
@@ -114,6 +208,31 @@ func AddSingleBrick(s *ports.Service, b ports.Brick, parameters map[string]strin
 		s.Parameters[k] = v
 	}
 
+	for _, p := range b.Parameters {
+		if !p.Secret {
+			continue
+		}
+		if s.Secrets == nil {
+			s.Secrets = make(map[string]string)
+		}
+		if _, ok := s.Secrets[p.Name]; !ok {
+			s.Secrets[p.Name] = ports.DefaultSecretReference
+		}
+	}
+
+	return nil
+}
+
+// addBrick adds a brick to the service and records the digest of the brick's content
+func addBrick(s *ports.Service, b ports.Brick, parameters map[string]string) error {
+	digest, err := brickDigest(b)
+	if err != nil {
+		return err
+	}
+	if err := AddSingleBrick(s, b, parameters); err != nil {
+		return err
+	}
+	s.BrickIds[len(s.BrickIds)-1].Digest = digest
 	return nil
 }
 
@@ -248,6 +367,27 @@ func replaceParameters(content string, parameters map[string]string) string {
 	return content
 }
 
+// splitBrickReference splits a brick reference of the form 'id' or 'id@constraint' (e.g. 'repo-postgres@^1.0.0')
+func splitBrickReference(ref string) (id string, constraint string) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// findBrick returns the brick for a brick reference. The highest version is used if no constraint is given.
+func findBrick(db ports.BrickDB, ref string) (ports.Brick, error) {
+	id, constraintStr := splitBrickReference(ref)
+	if constraintStr == "" {
+		return db.Brick(id)
+	}
+	constraint, err := ports.ParseVersionConstraint(constraintStr)
+	if err != nil {
+		return ports.Brick{}, err
+	}
+	return db.BrickMatching(id, constraint)
+}
+
 func GetBricksRecursive(brickId string, db ports.BrickDB, parentBrickIds map[string]bool) ([]ports.Brick, error) {
 
 	brickIds := make(map[string]bool)
@@ -257,11 +397,11 @@ func GetBricksRecursive(brickId string, db ports.BrickDB, parentBrickIds map[str
 
 	bricks := []ports.Brick{}
 
-	if brickIds[brickId] == true {
-		return nil, fmt.Errorf("cyclic brick dependency")
+	if id, _ := splitBrickReference(brickId); brickIds[id] == true {
+		return nil, CyclicBrickDependency
 	}
 
-	brick, err := db.Brick(brickId)
+	brick, err := findBrick(db, brickId)
 	if err != nil {
 		return bricks, fmt.Errorf("invalid brick %s", brickId)
 	}
@@ -306,6 +446,9 @@ func (s ServiceApi) Add(templateName string, parentDir string, parameterResolver
 		return service, err
 	}
 
+	if err := validateParameters(bricks, nil, parameterResolver); err != nil {
+		return service, err
+	}
 	parameters, err := ResolveParameterSlice(bricks, parameterResolver)
 	if err != nil {
 		return service, err
@@ -325,7 +468,7 @@ func (s ServiceApi) Add(templateName string, parentDir string, parameterResolver
 	}
 
 	for _, brick := range bricks {
-		if err := AddSingleBrick(&service, brick, parameters); err != nil {
+		if err := addBrick(&service, brick, parameters); err != nil {
 			return service, err
 		}
 	}
@@ -359,7 +502,7 @@ func (s ServiceApi) upgradeDependencyToVersion(service ports.Service, d ports.Pa
 // sortedVersions must range from current version to latest version to be considered (must at least have one entry)
 // isWorkinbg is a predicate that checks if a specific version is working
 // returns latest working version
-func findLatestWorkingVersion(sortedVersions []SemanticVersion, isWorking func(v SemanticVersion) bool) SemanticVersion {
+func findLatestWorkingVersion(sortedVersions []ports.SemanticVersion, isWorking func(v ports.SemanticVersion) bool) ports.SemanticVersion {
 
 	latestWorkingVersion := sortedVersions[0]
 	sortedVersions = sortedVersions[1:]
@@ -380,8 +523,8 @@ func findLatestWorkingVersion(sortedVersions []SemanticVersion, isWorking func(v
 	return latestWorkingVersion
 }
 
-func filterSemvers(in []SemanticVersion, predicate func(SemanticVersion) bool) []SemanticVersion {
-	out := []SemanticVersion{}
+func filterSemvers(in []ports.SemanticVersion, predicate func(ports.SemanticVersion) bool) []ports.SemanticVersion {
+	out := []ports.SemanticVersion{}
 	for _, v := range in {
 		if predicate(v) {
 			out = append(out, v)
@@ -404,24 +547,24 @@ func (s ServiceApi) upgradeDependency(service ports.Service, d ports.PackageDepe
 	vus.latestAvailable = availableVersionStrings[len(availableVersionStrings)-1]
 
 	//2. check if we can use semantic versions
-	semvers := []SemanticVersion{}
-	currentSemver, err := ParseSemanticVersion(vus.previous)
+	semvers := []ports.SemanticVersion{}
+	currentSemver, err := ports.ParseSemanticVersion(vus.previous)
 	if err == nil {
-		semvers, err = ConvertToSemVer(availableVersionStrings)
+		semvers, err = ports.ConvertToSemVer(availableVersionStrings)
 	}
 
 	if err == nil {
 		//yes => use semantic versions
 
 		//a) sort versions
-		sort.Sort(ByVersion(semvers))
+		sort.Sort(ports.ByVersion(semvers))
 		vus.latestAvailable = semvers[len(semvers)-1].String()
 
 		//a) exclude all old versions
-		semvers = filterSemvers(semvers, func(v SemanticVersion) bool { return !Less(v, currentSemver) })
+		semvers = filterSemvers(semvers, func(v ports.SemanticVersion) bool { return !ports.Less(v, currentSemver) })
 		//b) if wanted, exclude all versions with a different major version
 		if keepMajorVersion {
-			semvers = filterSemvers(semvers, func(v SemanticVersion) bool { return currentSemver.Major == v.Major })
+			semvers = filterSemvers(semvers, func(v ports.SemanticVersion) bool { return currentSemver.Major == v.Major })
 		}
 
 		if len(semvers) == 0 { //this should not happen because the current version should always be included
@@ -435,7 +578,7 @@ func (s ServiceApi) upgradeDependency(service ports.Service, d ports.PackageDepe
 			return vus, nil
 		}
 
-		vus.latestWorking = findLatestWorkingVersion(semvers, func(v SemanticVersion) bool {
+		vus.latestWorking = findLatestWorkingVersion(semvers, func(v ports.SemanticVersion) bool {
 			fmt.Fprintf(s.Stdout, "trying to upgrade to %v...", v)
 			logFilename, err := s.upgradeDependencyToVersion(service, d, v.String())
 			if err == nil {
@@ -584,6 +727,122 @@ func (s ServiceApi) Describe(path string, writer io.Writer) error {
 	return nil
 }
 
+// verifyBrickDependency compares the recorded digest of a brick with the brick's content in the db.
+// changed is true if the content differs although the version is the same.
+func verifyBrickDependency(d ports.BrickDependency, db ports.BrickDB) (changed bool, message string) {
+	versions := db.BrickVersions(d.Id)
+	if len(versions) == 0 {
+		return false, "not available in any remote"
+	}
+	var brick *ports.Brick
+	for i := range versions {
+		if versions[i].Version == d.Version {
+			brick = &versions[i]
+		}
+	}
+	if brick == nil {
+		return false, "version is not available in the remotes. Unable to verify the content."
+	}
+	if d.Digest == "" {
+		return false, "no digest recorded. Unable to verify the content."
+	}
+	digest, err := brickDigest(*brick)
+	if err != nil {
+		return false, fmt.Sprintf("unable to compute digest (%v)", err)
+	}
+	if digest != d.Digest {
+		return true, fmt.Sprintf("content changed under stable version (recorded %s, remote %s)", d.Digest, digest)
+	}
+	return false, "ok"
+}
+
+func (s ServiceApi) VerifyBricks(path string, writer io.Writer) error {
+	service, err := s.ServicePersistence.Load(path)
+	if err != nil {
+		return err
+	}
+
+	db, err := s.BrickDBFactory.MakeAggregatedBrickDB(serviceRemotes(path, s.Configuration.Remotes()), s.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
+	}
+
+	changedCount := 0
+	for _, d := range service.BrickIds {
+		changed, message := verifyBrickDependency(d, db)
+		if changed {
+			changedCount++
+		}
+		fmt.Fprintf(writer, "%s %s: %s\n", d.Id, d.Version, message)
+	}
+
+	if changedCount > 0 {
+		return fmt.Errorf("%v of %v bricks changed under a stable version", changedCount, len(service.BrickIds))
+	}
+	return nil
+}
+
+// copyBrick copies the manifest and the files of a brick into targetDir
+func copyBrick(b ports.Brick, targetDir string) error {
+	for _, f := range append([]string{"manifest.yaml"}, b.Files...) {
+		content, err := ioutil.ReadFile(filepath.Join(b.BasePath, f))
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(targetDir, f)
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Vendor copies the exact versions of all bricks used by a service into the service so that it no longer depends on the content of the remotes
+func (s ServiceApi) Vendor(path string, writer io.Writer) error {
+	service, err := s.ServicePersistence.Load(path)
+	if err != nil {
+		return err
+	}
+
+	db, err := s.BrickDBFactory.MakeAggregatedBrickDB(serviceRemotes(path, s.Configuration.Remotes()), s.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
+	}
+
+	//vendor into a temp folder first as the previously vendored bricks may be the source
+	vendorDir := filepath.Join(path, vendoredBricksDir)
+	tempDir := vendorDir + ".tmp"
+	if err := os.RemoveAll(tempDir); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, d := range service.BrickIds {
+		brick, err := findBrickVersion(db, d.Id, d.Version)
+		if err != nil {
+			return err
+		}
+		if changed, message := verifyBrickDependency(d, db); changed {
+			fmt.Fprintf(writer, "warning: brick %s %s: %s\n", d.Id, d.Version, message)
+		}
+		if err := copyBrick(brick, filepath.Join(tempDir, fmt.Sprintf("%s-%s", d.Id, d.Version))); err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "vendored %s %s\n", d.Id, d.Version)
+	}
+
+	if err := os.RemoveAll(vendorDir); err != nil {
+		return err
+	}
+	if len(service.BrickIds) == 0 {
+		return nil
+	}
+	return os.Rename(tempDir, vendorDir)
+}
+
 func (s ServiceApi) Deploy(path string) error {
 	service, err := s.ServicePersistence.Load(path)
 	if err != nil {
@@ -260,6 +260,30 @@ func TestResolveParameterSlice(t *testing.T) {
 	}
 }
 
+func TestResolveParameterSlice_MissingParameters(t *testing.T) {
+	bricks := []ports.Brick{
+		{Id: "template", Parameters: []ports.BrickParameters{{Name: "a"}, {Name: "c", Description: "the c"}, {Name: "d"}}},
+		{Id: "extension", Parameters: []ports.BrickParameters{{Name: "d", Description: "the d"}, {Name: "e", Default: "d1"}, {Name: "f"}}},
+	}
+	_, err := ResolveParameterSlice(bricks, testResolver{})
+
+	want := ports.MissingParametersError{Parameters: []ports.MissingParameter{
+		{Name: "c", Description: "the c", Bricks: []string{"template"}},
+		{Name: "d", Description: "the d", Bricks: []string{"template", "extension"}},
+		{Name: "f", Bricks: []string{"extension"}},
+	}}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("ResolveParameterSlice() error = %#v, want %#v", err, want)
+	}
+	wantMessage := `unable to resolve values for 3 parameter(s):
+  c (brick template): the c
+  d (brick template, extension): the d
+  f (brick extension)`
+	if err.Error() != wantMessage {
+		t.Errorf("ResolveParameterSlice() error = %v, want %v", err.Error(), wantMessage)
+	}
+}
+
 func TestAddSingleBrick(t *testing.T) {
 
 	brick1TempDir, _ := ioutil.TempDir("", "brick1")
@@ -314,6 +338,22 @@ and even more content
 			},
 			wantErr: false,
 		},
+		{
+			name: "secret parameter",
+			args: args{
+				s:          &ports.Service{Id: "my_service", Dependencies: []ports.PackageDependency{}, Secrets: map[string]string{"DB_PASSWORD": "env:DB_PASSWORD"}},
+				b:          ports.Brick{Id: "b1", Version: "1.0.0", Parameters: []ports.BrickParameters{{Name: "DB_USER"}, {Name: "DB_PASSWORD", Secret: true}, {Name: "TOKEN", Secret: true}}},
+				parameters: map[string]string{"DB_USER": "sapper", "DB_PASSWORD": "pw", "TOKEN": "t"},
+			},
+			wantService: ports.Service{
+				Id:           "my_service",
+				BrickIds:     []ports.BrickDependency{{Id: "b1", Version: "1.0.0"}},
+				Dependencies: []ports.PackageDependency{},
+				Parameters:   map[string]string{"DB_USER": "sapper", "DB_PASSWORD": "pw", "TOKEN": "t"},
+				Secrets:      map[string]string{"DB_PASSWORD": "env:DB_PASSWORD", "TOKEN": ports.DefaultSecretReference},
+			},
+			wantErr: false,
+		},
 		{
 			name: "copy file",
 			args: args{
@@ -386,6 +426,7 @@ and that's it.
 type TestBrickDB struct {
 	initCalled   *bool
 	updateCalled *bool
+	lastUpdate   time.Time
 }
 
 func (db *TestBrickDB) Init(Path string) error {
@@ -421,19 +462,63 @@ func (db TestBrickDB) Brick(id string) (ports.Brick, error) {
 	return ports.Brick{}, fmt.Errorf("brick with id %s does not exist", id)
 }
 
-func (db *TestBrickDB) Update() error {
+func (db TestBrickDB) BrickVersions(id string) []ports.Brick {
+	if brick, err := db.Brick(id); err == nil {
+		return []ports.Brick{brick}
+	}
+	return []ports.Brick{}
+}
+
+func (db TestBrickDB) BrickMatching(id string, constraint ports.VersionConstraint) (ports.Brick, error) {
+	return db.Brick(id)
+}
+
+func (db *TestBrickDB) Update(output io.Writer) error {
 	if db.updateCalled != nil {
 		*db.updateCalled = true
 	}
+	fmt.Fprintln(output, "pulled")
+	return nil
+}
+
+func (db TestBrickDB) InvalidManifests() []ports.BrickManifestError {
 	return nil
 }
 
+func (db TestBrickDB) LastUpdate() time.Time {
+	return db.lastUpdate
+}
+
 func (db TestBrickDB) IsModified() (bool, string) {
 	return false, ""
 }
 
 var _ ports.BrickDB = (*TestBrickDB)(nil)
 
+func Test_splitBrickReference(t *testing.T) {
+	tests := []struct {
+		name           string
+		ref            string
+		wantId         string
+		wantConstraint string
+	}{
+		{name: "id only", ref: "repo-postgres", wantId: "repo-postgres", wantConstraint: ""},
+		{name: "id with constraint", ref: "repo-postgres@^1.0.0", wantId: "repo-postgres", wantConstraint: "^1.0.0"},
+		{name: "id with range", ref: "repo-postgres@>=1.0.0 <2.0.0", wantId: "repo-postgres", wantConstraint: ">=1.0.0 <2.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotId, gotConstraint := splitBrickReference(tt.ref)
+			if gotId != tt.wantId {
+				t.Errorf("splitBrickReference() id = %v, want %v", gotId, tt.wantId)
+			}
+			if gotConstraint != tt.wantConstraint {
+				t.Errorf("splitBrickReference() constraint = %v, want %v", gotConstraint, tt.wantConstraint)
+			}
+		})
+	}
+}
+
 func TestGetBricksRecursive(t *testing.T) {
 	type args struct {
 		brickId string
@@ -484,41 +569,135 @@ func TestGetBricksRecursive(t *testing.T) {
 	}
 }
 
+func Test_verifyBrickDependency(t *testing.T) {
+	brickDir, _ := ioutil.TempDir("", "verifyBrick*")
+	defer os.RemoveAll(brickDir) // clean up
+	ioutil.WriteFile(filepath.Join(brickDir, "manifest.yaml"), []byte("id: b1"), 0666)
+	ioutil.WriteFile(filepath.Join(brickDir, "test.txt"), []byte("some content"), 0666)
+
+	brick := ports.Brick{Id: "b1", Version: "1.0.0", BasePath: brickDir, Files: []string{"test.txt"}}
+	digest, _ := brickDigest(brick)
+	db := FakeBrickDB{"b1": brick}
+
+	tests := []struct {
+		name        string
+		d           ports.BrickDependency
+		wantChanged bool
+	}{
+		{name: "unchanged", d: ports.BrickDependency{Id: "b1", Version: "1.0.0", Digest: digest}, wantChanged: false},
+		{name: "changed", d: ports.BrickDependency{Id: "b1", Version: "1.0.0", Digest: "sha256:1234"}, wantChanged: true},
+		{name: "different version", d: ports.BrickDependency{Id: "b1", Version: "0.9.0", Digest: "sha256:1234"}, wantChanged: false},
+		{name: "no digest", d: ports.BrickDependency{Id: "b1", Version: "1.0.0"}, wantChanged: false},
+		{name: "missing brick", d: ports.BrickDependency{Id: "b2", Version: "1.0.0", Digest: digest}, wantChanged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gotChanged, message := verifyBrickDependency(tt.d, db); gotChanged != tt.wantChanged {
+				t.Errorf("verifyBrickDependency() changed = %v (%s), want %v", gotChanged, message, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func Test_serviceRemotes(t *testing.T) {
+	serviceDir, _ := ioutil.TempDir("", "serviceRemotes*")
+	defer os.RemoveAll(serviceDir) // clean up
+	remotes := []ports.Remote{{Name: "a"}}
+
+	if got := serviceRemotes(serviceDir, remotes); !reflect.DeepEqual(got, remotes) {
+		t.Errorf("serviceRemotes() without vendored bricks = %v, want %v", got, remotes)
+	}
+
+	os.MkdirAll(filepath.Join(serviceDir, ".sapper", "bricks"), 0777)
+	want := []ports.Remote{{Name: "vendored", Kind: ports.FilesystemRemote, Src: filepath.Join(serviceDir, ".sapper", "bricks")}, {Name: "a"}}
+	if got := serviceRemotes(serviceDir, remotes); !reflect.DeepEqual(got, want) {
+		t.Errorf("serviceRemotes() with vendored bricks = %v, want %v", got, want)
+	}
+}
+
+func TestServiceApi_Vendor(t *testing.T) {
+	brickDir, _ := ioutil.TempDir("", "vendorBrick*")
+	defer os.RemoveAll(brickDir) // clean up
+	serviceDir, _ := ioutil.TempDir("", "vendorService*")
+	defer os.RemoveAll(serviceDir) // clean up
+	ioutil.WriteFile(filepath.Join(brickDir, "manifest.yaml"), []byte("id: b1\nversion: 1.0.0"), 0666)
+	os.MkdirAll(filepath.Join(brickDir, "sub"), 0777)
+	ioutil.WriteFile(filepath.Join(brickDir, "sub", "test.txt"), []byte("some content"), 0666)
+	brick := ports.Brick{Id: "b1", Version: "1.0.0", BasePath: brickDir, Files: []string{"sub/test.txt"}}
+
+	tests := []struct {
+		name      string
+		brickIds  []ports.BrickDependency
+		wantErr   bool
+		wantFiles []string
+	}{
+		{name: "available brick", brickIds: []ports.BrickDependency{{Id: "b1", Version: "1.0.0"}}, wantErr: false, wantFiles: []string{"b1-1.0.0/manifest.yaml", "b1-1.0.0/sub/test.txt"}},
+		{name: "unavailable version", brickIds: []ports.BrickDependency{{Id: "b1", Version: "0.9.0"}}, wantErr: true},
+		{name: "no bricks", brickIds: []ports.BrickDependency{}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.RemoveAll(filepath.Join(serviceDir, ".sapper"))
+			s := ServiceApi{
+				Configuration:      &MockConfiguration{},
+				BrickDBFactory:     FakeBrickDBFactory{db: FakeBrickDB{"b1": brick}},
+				ServicePersistence: &FakeServicePersistence{service: ports.Service{Id: "service", BrickIds: tt.brickIds}},
+			}
+			if err := s.Vendor(serviceDir, ioutil.Discard); (err != nil) != tt.wantErr {
+				t.Errorf("ServiceApi.Vendor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, f := range tt.wantFiles {
+				if _, err := os.Stat(filepath.Join(serviceDir, ".sapper", "bricks", f)); err != nil {
+					t.Errorf("ServiceApi.Vendor() did not vendor %s", f)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(serviceDir, ".sapper", "bricks.tmp")); err == nil {
+				t.Errorf("ServiceApi.Vendor() did not clean up the temp folder")
+			}
+		})
+	}
+}
+
+func SemVer(s string) ports.SemanticVersion {
+	v, _ := ports.ParseSemanticVersion(s)
+	return v
+}
+
 func Test_findLatestWorkingVersion(t *testing.T) {
 
 	type args struct {
-		versions  []SemanticVersion
-		isWorking map[SemanticVersion]bool
+		versions  []ports.SemanticVersion
+		isWorking map[ports.SemanticVersion]bool
 	}
 	tests := []struct {
 		name string
 		args args
-		want SemanticVersion
+		want ports.SemanticVersion
 	}{
-		{name: "single not working version", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3")}}, want: SemVer("1.2.2")},
-		{name: "single working version", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3")}, isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): true}}, want: SemVer("1.2.3")},
-		{name: "two working versions", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4")},
-			isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true}},
+		{name: "single not working version", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3")}}, want: SemVer("1.2.2")},
+		{name: "single working version", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3")}, isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): true}}, want: SemVer("1.2.3")},
+		{name: "two working versions", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4")},
+			isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true}},
 			want: SemVer("1.2.4")},
-		{name: "two versions: higher version working", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4")},
-			isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): false, SemVer("1.2.4"): true}},
+		{name: "two versions: higher version working", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4")},
+			isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): false, SemVer("1.2.4"): true}},
 			want: SemVer("1.2.4")},
-		{name: "two versions: lower version working", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4")},
-			isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): false}},
+		{name: "two versions: lower version working", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4")},
+			isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): false}},
 			want: SemVer("1.2.3")},
-		{name: "three versions: center version working", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4"), SemVer("1.2.5")},
-			isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true, SemVer("1.2.5"): false}},
+		{name: "three versions: center version working", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4"), SemVer("1.2.5")},
+			isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true, SemVer("1.2.5"): false}},
 			want: SemVer("1.2.4")},
-		{name: "five versions: 2nd version working", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4"), SemVer("1.2.5"), SemVer("1.2.6"), SemVer("1.2.7")},
-			isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true, SemVer("1.2.5"): false, SemVer("1.2.6"): false, SemVer("1.2.7"): false}},
+		{name: "five versions: 2nd version working", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4"), SemVer("1.2.5"), SemVer("1.2.6"), SemVer("1.2.7")},
+			isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true, SemVer("1.2.5"): false, SemVer("1.2.6"): false, SemVer("1.2.7"): false}},
 			want: SemVer("1.2.4")},
-		{name: "five versions: 4th version working", args: args{versions: []SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4"), SemVer("1.2.5"), SemVer("1.2.6"), SemVer("1.2.7")},
-			isWorking: map[SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true, SemVer("1.2.5"): true, SemVer("1.2.6"): true, SemVer("1.2.7"): false}},
+		{name: "five versions: 4th version working", args: args{versions: []ports.SemanticVersion{SemVer("1.2.2"), SemVer("1.2.3"), SemVer("1.2.4"), SemVer("1.2.5"), SemVer("1.2.6"), SemVer("1.2.7")},
+			isWorking: map[ports.SemanticVersion]bool{SemVer("1.2.3"): true, SemVer("1.2.4"): true, SemVer("1.2.5"): true, SemVer("1.2.6"): true, SemVer("1.2.7"): false}},
 			want: SemVer("1.2.6")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := findLatestWorkingVersion(tt.args.versions, func(v SemanticVersion) bool {
+			got := findLatestWorkingVersion(tt.args.versions, func(v ports.SemanticVersion) bool {
 				val, ok := tt.args.isWorking[v]
 				return ok == true && val == true
 			})
@@ -531,21 +710,21 @@ func Test_findLatestWorkingVersion(t *testing.T) {
 
 func Test_filterSemvers(t *testing.T) {
 	type args struct {
-		in        []SemanticVersion
-		predicate func(SemanticVersion) bool
+		in        []ports.SemanticVersion
+		predicate func(ports.SemanticVersion) bool
 	}
 	tests := []struct {
 		name string
 		args args
-		want []SemanticVersion
+		want []ports.SemanticVersion
 	}{
 		{
 			name: "two entries, keep major 1",
 			args: args{
-				in:        []SemanticVersion{SemVer("1.0.0"), SemVer("2.0.0")},
-				predicate: func(v SemanticVersion) bool { return v.Major == 1 },
+				in:        []ports.SemanticVersion{SemVer("1.0.0"), SemVer("2.0.0")},
+				predicate: func(v ports.SemanticVersion) bool { return v.Major == 1 },
 			},
-			want: []SemanticVersion{SemVer("1.0.0")},
+			want: []ports.SemanticVersion{SemVer("1.0.0")},
 		},
 	}
 	for _, tt := range tests {
@@ -973,3 +1152,58 @@ func TestServiceApi_Run(t *testing.T) {
 		})
 	}
 }
+
+type testPrompter struct {
+	testResolver
+	requests []ports.ParameterRequest
+	values   map[string]string
+}
+
+func (tp *testPrompter) Prompt(requests []ports.ParameterRequest) (map[string]string, error) {
+	tp.requests = requests
+	values := map[string]string{}
+	for _, r := range requests {
+		values[r.Parameter.Name] = r.Value
+	}
+	for k, v := range tp.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func TestResolveParameterSlice_Prompt(t *testing.T) {
+	bricks := []ports.Brick{
+		{Id: "template", Parameters: []ports.BrickParameters{{Name: "a"}, {Name: "c", Description: "the c"}}},
+		{Id: "extension", Parameters: []ports.BrickParameters{{Name: "c"}, {Name: "d", Pattern: "[0-9]+"}}},
+	}
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "all prompted", values: map[string]string{"c": "3", "d": "4"}, want: map[string]string{"a": "1", "c": "3", "d": "4"}},
+		{name: "some still missing", values: map[string]string{"c": "3"}, wantErr: true},
+		{name: "invalid value", values: map[string]string{"c": "3", "d": "four"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := &testPrompter{values: tt.values}
+			got, err := ResolveParameterSlice(bricks, tp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveParameterSlice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveParameterSlice() = %v, want %v", got, tt.want)
+			}
+			wantRequests := []ports.ParameterRequest{
+				{Brick: "template", Parameter: ports.BrickParameters{Name: "a"}, Value: "1"},
+				{Brick: "template", Parameter: ports.BrickParameters{Name: "c", Description: "the c"}},
+				{Brick: "extension", Parameter: ports.BrickParameters{Name: "d", Pattern: "[0-9]+"}},
+			}
+			if !reflect.DeepEqual(tp.requests, wantRequests) {
+				t.Errorf("ResolveParameterSlice() requests = %v, want %v", tp.requests, wantRequests)
+			}
+		})
+	}
+}
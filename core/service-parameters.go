@@ -0,0 +1,274 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/seboste/sapper/ports"
+)
+
+// findBrickVersion returns the brick with exactly the given version
+func findBrickVersion(db ports.BrickDB, id string, version string) (ports.Brick, error) {
+	for _, b := range db.BrickVersions(id) {
+		if b.Version == version {
+			return b, nil
+		}
+	}
+	return ports.Brick{}, fmt.Errorf("brick %s %s is not available in any remote", id, version)
+}
+
+// renderService adds the bricks with the given parameters to an empty service in dir, i.e. it reproduces the files as they have been
+// generated by sapper
+func renderService(bricks []ports.Brick, parameters map[string]string, dir string) error {
+	s := ports.Service{Path: dir}
+	for _, b := range bricks {
+		if err := AddSingleBrick(&s, b, parameters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchLines returns for each line of a the index of the same line in b or -1 if the line has no counterpart in b.
+// The lines are matched along the longest common subsequence.
+func matchLines(a []string, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	//most changes are local => only compute the longest common subsequence between the common prefix and suffix
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		match[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		match[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lcs := make([][]int32, len(am)+1) //lcs[i][j] is the length of the longest common subsequence of am[i:] and bm[j:]
+	for i := range lcs {
+		lcs[i] = make([]int32, len(bm)+1)
+	}
+	for i := len(am) - 1; i >= 0; i-- {
+		for j := len(bm) - 1; j >= 0; j-- {
+			if am[i] == bm[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(am) && j < len(bm); {
+		if am[i] == bm[j] {
+			match[prefix+i] = prefix + j
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return match
+}
+
+// renderConflict is a line that differs between two renderings of a file but has been changed by the user
+type renderConflict struct {
+	previous string
+	rendered string
+}
+
+// mergeRendering applies the changes between the previous and the new rendering of a file to its current content. previous and
+// rendered must have the same number of lines. Lines that have been changed by the user are left untouched and reported as conflicts.
+func mergeRendering(previous string, rendered string, current string) (string, []renderConflict) {
+	previousLines, renderedLines, currentLines := strings.Split(previous, "\n"), strings.Split(rendered, "\n"), strings.Split(current, "\n")
+	match := matchLines(previousLines, currentLines)
+	conflicts := []renderConflict{}
+	for i := range previousLines {
+		if previousLines[i] == renderedLines[i] {
+			continue
+		}
+		if match[i] < 0 {
+			conflicts = append(conflicts, renderConflict{previous: previousLines[i], rendered: renderedLines[i]})
+			continue
+		}
+		currentLines[match[i]] = renderedLines[i]
+	}
+	return strings.Join(currentLines, "\n"), conflicts
+}
+
+// SetParameter changes the value of a parameter of a service and re-renders the files of the service's bricks that use the parameter.
+// The changes are applied to the files unless the affected lines have been changed by the user. Such conflicts are reported.
+func (s ServiceApi) SetParameter(path string, name string, value string, writer io.Writer) error {
+	if value == "" || strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("invalid value for parameter %s. The value must be a single, non-empty line", name)
+	}
+
+	service, err := s.ServicePersistence.Load(path)
+	if err != nil {
+		return err
+	}
+
+	db, err := s.BrickDBFactory.MakeAggregatedBrickDB(serviceRemotes(path, s.Configuration.Remotes()), s.Configuration.DefaultRemotesDir())
+	if err != nil {
+		return err
+	}
+
+	bricks := []ports.Brick{}
+	declared := false
+	shownValue := value
+	for _, d := range service.BrickIds {
+		brick, err := findBrickVersion(db, d.Id, d.Version)
+		if err != nil {
+			return fmt.Errorf("%v. Unable to re-render the service", err)
+		}
+		if changed, message := verifyBrickDependency(d, db); changed {
+			fmt.Fprintf(writer, "warning: brick %s %s: %s\n", d.Id, d.Version, message)
+		}
+		for _, p := range brick.Parameters {
+			if p.Name == name {
+				if err := p.Validate(value); err != nil {
+					return err
+				}
+				declared = true
+				if p.Secret {
+					shownValue = "********"
+				}
+			}
+		}
+		bricks = append(bricks, brick)
+	}
+	if !declared {
+		return fmt.Errorf("parameter %s is not declared by any brick of service %s", name, service.Id)
+	}
+	if reference := service.Secrets[name]; strings.HasPrefix(reference, ports.EnvSecretReferencePrefix) { //sapper cannot change the variable
+		return fmt.Errorf("parameter %s is read from the environment variable %s. Change the variable instead", name, strings.TrimPrefix(reference, ports.EnvSecretReferencePrefix))
+	}
+	if _, resolved := service.Parameters[name]; !resolved { //e.g. a secret whose value is not available
+		return fmt.Errorf("the current value of parameter %s is unknown. Unable to re-render the service", name)
+	}
+	if service.Parameters[name] == value {
+		fmt.Fprintf(writer, "parameter %s is already set to %s\n", name, shownValue)
+		return nil
+	}
+
+	//files of the bricks that use the parameter
+	placeholder := "<<<" + name + ">>>"
+	affected := map[string]bool{}
+	for _, b := range bricks {
+		for _, f := range b.Files {
+			content, err := ioutil.ReadFile(filepath.Join(b.BasePath, f))
+			if err != nil {
+				return err
+			}
+			if strings.Contains(string(content), placeholder) {
+				affected[f] = true
+			}
+		}
+	}
+	files := []string{}
+	for f := range affected {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	previousParameters := map[string]string{}
+	parameters := map[string]string{}
+	for k, v := range service.Parameters {
+		previousParameters[k] = v
+		parameters[k] = v
+	}
+	parameters[name] = value
+
+	tempDir, err := ioutil.TempDir("", "sapper_set_param_*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	previousDir, renderedDir := filepath.Join(tempDir, "previous"), filepath.Join(tempDir, "rendered")
+	if err := renderService(bricks, previousParameters, previousDir); err != nil {
+		return err
+	}
+	if err := renderService(bricks, parameters, renderedDir); err != nil {
+		return err
+	}
+
+	conflictCount := 0
+	for _, f := range files {
+		previous, err := ioutil.ReadFile(filepath.Join(previousDir, f))
+		if err != nil {
+			return err
+		}
+		rendered, err := ioutil.ReadFile(filepath.Join(renderedDir, f))
+		if err != nil {
+			return err
+		}
+		if string(previous) == string(rendered) {
+			continue
+		}
+
+		current, err := ioutil.ReadFile(filepath.Join(path, f))
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(writer, "conflict in %s: the file has been deleted\n", f)
+			conflictCount++
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		merged := string(rendered)
+		conflicts := []renderConflict{}
+		if strings.Count(string(previous), "\n") != strings.Count(string(rendered), "\n") { //only happens for values spanning several lines
+			if string(current) != string(previous) {
+				fmt.Fprintf(writer, "conflict in %s: the file has been changed and cannot be re-rendered\n", f)
+				conflictCount++
+				continue
+			}
+		} else if string(current) != string(previous) {
+			merged, conflicts = mergeRendering(string(previous), string(rendered), string(current))
+		}
+		if merged != string(current) {
+			if err := ioutil.WriteFile(filepath.Join(path, f), []byte(merged), 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(writer, "updated %s\n", f)
+		}
+		for _, c := range conflicts {
+			previousLine, renderedLine := strings.TrimSpace(c.previous), strings.TrimSpace(c.rendered)
+			if shownValue != value { //do not show the values of secret parameters
+				if previousValue := service.Parameters[name]; previousValue != "" {
+					previousLine = strings.ReplaceAll(previousLine, previousValue, shownValue)
+				}
+				renderedLine = strings.ReplaceAll(renderedLine, value, shownValue)
+			}
+			fmt.Fprintf(writer, "conflict in %s: the line '%s' has been changed. Change it to '%s' manually\n", f, previousLine, renderedLine)
+		}
+		conflictCount += len(conflicts)
+	}
+
+	if service.Parameters == nil {
+		service.Parameters = map[string]string{}
+	}
+	service.Parameters[name] = value
+	if err := s.ServicePersistence.Save(service); err != nil {
+		return err
+	}
+
+	if conflictCount > 0 {
+		return fmt.Errorf("parameter %s has been set to %s, but %d conflict(s) need to be resolved manually", name, shownValue, conflictCount)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func Test_matchLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []string
+		b    []string
+		want []int
+	}{
+		{name: "equal", a: []string{"1", "2", "3"}, b: []string{"1", "2", "3"}, want: []int{0, 1, 2}},
+		{name: "inserted lines", a: []string{"1", "2", "3"}, b: []string{"0", "1", "x", "2", "3", "4"}, want: []int{1, 3, 4}},
+		{name: "changed line", a: []string{"1", "2", "3"}, b: []string{"1", "x", "3"}, want: []int{0, -1, 2}},
+		{name: "deleted line", a: []string{"1", "2", "3"}, b: []string{"1", "3"}, want: []int{0, -1, 1}},
+		{name: "moved line", a: []string{"1", "2", "3", "4"}, b: []string{"1", "3", "4", "2"}, want: []int{0, -1, 1, 2}},
+		{name: "empty", a: []string{}, b: []string{"1"}, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeRendering(t *testing.T) {
+	previous := "int main() {\n  listen(8080);\n  return 0;\n}\n"
+	rendered := "int main() {\n  listen(9090);\n  return 0;\n}\n"
+	tests := []struct {
+		name          string
+		current       string
+		want          string
+		wantConflicts []renderConflict
+	}{
+		{name: "unchanged file", current: previous, want: rendered, wantConflicts: []renderConflict{}},
+		{name: "lines added by the user",
+			current:       "#include <x>\nint main() {\n  init();\n  listen(8080);\n  return 0;\n}\n",
+			want:          "#include <x>\nint main() {\n  init();\n  listen(9090);\n  return 0;\n}\n",
+			wantConflicts: []renderConflict{},
+		},
+		{name: "affected line changed by the user",
+			current:       "int main() {\n  listen(8080, true);\n  return 0;\n}\n",
+			want:          "int main() {\n  listen(8080, true);\n  return 0;\n}\n",
+			wantConflicts: []renderConflict{{previous: "  listen(8080);", rendered: "  listen(9090);"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeRendering(previous, rendered, tt.current)
+			if got != tt.want {
+				t.Errorf("mergeRendering() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("mergeRendering() conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestServiceApi_SetParameter(t *testing.T) {
+	brickDir, _ := ioutil.TempDir("", "setParamBrick*")
+	defer os.RemoveAll(brickDir) // clean up
+	os.MkdirAll(filepath.Join(brickDir, "app"), 0777)
+	ioutil.WriteFile(filepath.Join(brickDir, "app", "main.cpp"), []byte("int main() {\n  listen(<<<PORT>>>);\n  // <<<SAPPER SECTION BEGIN MAIN>>>\n  // <<<SAPPER SECTION END MAIN>>>\n}\n"), 0666)
+	ioutil.WriteFile(filepath.Join(brickDir, "README.md"), []byte("# <<<NAME>>>\n"), 0666)
+	template := ports.Brick{Id: "tmpl", Version: "1.0.0", BasePath: brickDir, Files: []string{"app/main.cpp", "README.md"},
+		Parameters: []ports.BrickParameters{{Name: "NAME"}, {Name: "PORT", Pattern: "[0-9]+"}}}
+
+	extensionDir, _ := ioutil.TempDir("", "setParamExtension*")
+	defer os.RemoveAll(extensionDir) // clean up
+	os.MkdirAll(filepath.Join(extensionDir, "app"), 0777)
+	ioutil.WriteFile(filepath.Join(extensionDir, "app", "main.cpp"), []byte("  // <<<SAPPER SECTION BEGIN APPEND MAIN>>>\n  log(\"listening on <<<PORT>>>\");\n  // <<<SAPPER SECTION END APPEND MAIN>>>\n"), 0666)
+	extension := ports.Brick{Id: "ext", Version: "1.0.0", BasePath: extensionDir, Files: []string{"app/main.cpp"}, Parameters: []ports.BrickParameters{{Name: "PORT"}}}
+
+	generated := "int main() {\n  listen(8080);\n  // <<<SAPPER SECTION BEGIN MAIN>>>\n  log(\"listening on 8080\");\n  // <<<SAPPER SECTION END MAIN>>>\n}\n"
+
+	tests := []struct {
+		name          string
+		parameters    map[string]string
+		secrets       map[string]string
+		parameter     string
+		value         string
+		mainCpp       string
+		wantMainCpp   string
+		wantOutput    []string
+		wantErr       bool
+		wantParameter string
+	}{
+		{name: "unchanged service", parameter: "PORT", value: "9090", mainCpp: generated,
+			wantMainCpp:   strings.ReplaceAll(generated, "8080", "9090"),
+			wantOutput:    []string{"updated app/main.cpp"},
+			wantParameter: "9090",
+		},
+		{name: "service with own changes", parameter: "PORT", value: "9090",
+			mainCpp:       strings.Replace(generated, "int main() {\n", "int main() {\n  init();\n", 1),
+			wantMainCpp:   strings.Replace(strings.ReplaceAll(generated, "8080", "9090"), "int main() {\n", "int main() {\n  init();\n", 1),
+			wantParameter: "9090",
+		},
+		{name: "conflict", parameter: "PORT", value: "9090",
+			mainCpp:       strings.Replace(generated, "listen(8080);", "listen(8080, true);", 1),
+			wantMainCpp:   strings.Replace(strings.ReplaceAll(generated, "8080", "9090"), "listen(9090);", "listen(8080, true);", 1),
+			wantOutput:    []string{"updated app/main.cpp", "conflict in app/main.cpp: the line 'listen(8080);' has been changed. Change it to 'listen(9090);' manually"},
+			wantErr:       true,
+			wantParameter: "9090",
+		},
+		{name: "invalid value", parameter: "PORT", value: "http", mainCpp: generated, wantMainCpp: generated, wantErr: true, wantParameter: "8080"},
+		{name: "undeclared parameter", parameter: "HOST", value: "localhost", mainCpp: generated, wantMainCpp: generated, wantErr: true, wantParameter: "8080"},
+		{name: "same value", parameter: "PORT", value: "8080", mainCpp: generated, wantMainCpp: generated, wantParameter: "8080"},
+		{name: "unresolved value", parameters: map[string]string{"NAME": "my-service"}, parameter: "PORT", value: "9090", mainCpp: generated, wantMainCpp: generated, wantErr: true},
+		{name: "secret from environment", secrets: map[string]string{"PORT": "env:PORT"}, parameter: "PORT", value: "9090", mainCpp: generated, wantMainCpp: generated, wantErr: true, wantParameter: "8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceDir, _ := ioutil.TempDir("", "setParamService*")
+			defer os.RemoveAll(serviceDir) // clean up
+			os.MkdirAll(filepath.Join(serviceDir, "app"), 0777)
+			ioutil.WriteFile(filepath.Join(serviceDir, "app", "main.cpp"), []byte(tt.mainCpp), 0666)
+			ioutil.WriteFile(filepath.Join(serviceDir, "README.md"), []byte("# my-service\n"), 0666)
+
+			parameters := tt.parameters
+			if parameters == nil {
+				parameters = map[string]string{"NAME": "my-service", "PORT": "8080"}
+			}
+			sp := &FakeServicePersistence{service: ports.Service{Id: "my-service",
+				BrickIds:   []ports.BrickDependency{{Id: "tmpl", Version: "1.0.0"}, {Id: "ext", Version: "1.0.0"}},
+				Parameters: parameters, Secrets: tt.secrets}}
+			s := ServiceApi{
+				Configuration:      &MockConfiguration{},
+				BrickDBFactory:     FakeBrickDBFactory{db: FakeBrickDB{"tmpl": template, "ext": extension}},
+				ServicePersistence: sp,
+			}
+
+			var output bytes.Buffer
+			if err := s.SetParameter(serviceDir, tt.parameter, tt.value, &output); (err != nil) != tt.wantErr {
+				t.Errorf("ServiceApi.SetParameter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mainCpp, _ := ioutil.ReadFile(filepath.Join(serviceDir, "app", "main.cpp"))
+			if string(mainCpp) != tt.wantMainCpp {
+				t.Errorf("ServiceApi.SetParameter() main.cpp = %q, want %q", string(mainCpp), tt.wantMainCpp)
+			}
+			if readme, _ := ioutil.ReadFile(filepath.Join(serviceDir, "README.md")); string(readme) != "# my-service\n" {
+				t.Errorf("ServiceApi.SetParameter() changed README.md = %q", string(readme))
+			}
+			for _, o := range tt.wantOutput {
+				if !strings.Contains(output.String(), o) {
+					t.Errorf("ServiceApi.SetParameter() output = %q, want it to contain %q", output.String(), o)
+				}
+			}
+			if got := sp.service.Parameters["PORT"]; got != tt.wantParameter {
+				t.Errorf("ServiceApi.SetParameter() PORT = %v, want %v", got, tt.wantParameter)
+			}
+		})
+	}
+}
@@ -4,21 +4,20 @@ import (
 	"os"
 
 	brickDb "github.com/seboste/sapper/adapters/brick-db"
+	brickPackager "github.com/seboste/sapper/adapters/brick-packager"
 	configuration "github.com/seboste/sapper/adapters/configuration"
 	dependencyManager "github.com/seboste/sapper/adapters/dependency-manager"
 	"github.com/seboste/sapper/adapters/service"
+	versionControl "github.com/seboste/sapper/adapters/version-control"
 	"github.com/seboste/sapper/cmd"
 	"github.com/seboste/sapper/core"
 )
 
 func main() {
 
-	config, err := configuration.MakeFilesystemConfiguration()
-	if err != nil {
-		panic(err)
-	}
+	config := configuration.FileSystemConfiguration{}
 
-	brickDbFactory := brickDb.Factory{}
+	brickDbFactory := brickDb.Factory{Configuration: &config}
 
 	dependencyManager := dependencyManager.ConanDependencyManager{}
 	servicePersistence := service.FileSystemServicePersistence{DependencyReader: dependencyManager}
@@ -41,6 +40,10 @@ func main() {
 		PackageDependencyReader: dependencyManager,
 		PackageDependencyWriter: dependencyManager,
 		DependencyInfo:          dependencyManager,
+		BrickPackager:           brickPackager.TarGzBrickPackager{},
+		ManifestSchema:          brickDb.ManifestSchema{},
+		ManifestWriter:          brickDb.ManifestWriter{},
+		VersionControl:          versionControl.GitVersionControl{},
 		ServicePersistence:      servicePersistence,
 		ServiceApi:              serviceApi,
 	}
@@ -51,7 +54,21 @@ func main() {
 		BrickUpgrader:  brickApi,
 	}
 
-	cmd.SetApis(brickApi, serviceApi, remoteApi)
+	profileApi := core.ProfileApi{
+		Configuration: &config,
+	}
+
+	configApi := core.ConfigApi{
+		Configuration: &config,
+		Editor:        &config,
+	}
+
+	cmd.SetConfigLoader(func(configFile string, profile string, projectDir string) error {
+		var err error
+		config, err = configuration.MakeFilesystemConfiguration(configFile, profile, projectDir)
+		return err
+	})
+	cmd.SetApis(brickApi, serviceApi, remoteApi, profileApi, configApi)
 	cmd.SetVersion("0.2.0")
 	cmd.Execute()
 }
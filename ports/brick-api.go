@@ -2,16 +2,69 @@ package ports
 
 import (
 	"io"
+	"time"
 )
 
 type BrickUpgrader interface {
 	UpgradeInDB(brickId string, db BrickDB) error
 }
 
+// BrickOrigin describes where a brick has been found
+type BrickOrigin struct {
+	Remote Remote
+	Brick  Brick
+	Pinned bool //the brick is pinned to the remote
+}
+
+type BrickTestStatus int
+
+const (
+	BrickTestPassed BrickTestStatus = iota
+	BrickTestSetupFailed
+	BrickTestBuildFailed
+	BrickTestTestFailed
+)
+
+func (s BrickTestStatus) String() string {
+	switch s {
+	case BrickTestPassed:
+		return "passed"
+	case BrickTestSetupFailed:
+		return "setup failed"
+	case BrickTestBuildFailed:
+		return "build failed"
+	case BrickTestTestFailed:
+		return "test failed"
+	}
+	return "unknown"
+}
+
+// BrickTestResult is the outcome of building and testing a service with a single brick or a pair of bricks
+type BrickTestResult struct {
+	BrickIds []string
+	Status   BrickTestStatus
+	Message  string
+	Duration time.Duration
+}
+
+// BrickTestReport is the compatibility matrix of a set of bricks for a given template
+type BrickTestReport struct {
+	Template string
+	BrickIds []string
+	Results  []BrickTestResult
+}
+
 type BrickApi interface {
 	Add(servicePath string, brickId string, parameterResolver ParameterResolver) error
 	Upgrade(brickId string) error
 	Describe(brickId string, writer io.Writer) error
-	List() []Brick
-	Search(term string) []Brick
+	List(kinds []BrickKind, allVersions bool) []Brick
+	Search(term string, kinds []BrickKind) []Brick
+	Which(brickId string) ([]BrickOrigin, error)
+	Origins() (map[string][]BrickOrigin, error)
+	Pack(brickPath string, outputDir string) (BrickPackage, error)
+	Publish(packagePath string, remoteName string) error
+	Schema(writer io.Writer) error
+	Extract(servicePath string, since string, target string, writer io.Writer) (Brick, error)
+	Test(brickIds []string, template string, pairs bool, writer io.Writer) (BrickTestReport, error)
 }
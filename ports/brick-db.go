@@ -3,7 +3,10 @@ package ports
 import (
 	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,8 +14,37 @@ import (
 var BrickNotFound = errors.New("brick not found")
 
 type BrickParameters struct {
-	Name    string
-	Default string
+	Name        string
+	Default     string
+	Description string
+	Choices     []string //allowed values. Optional
+	Pattern     string   //regular expression the value must match completely. Optional
+	Secret      bool     //the value is not shown while being entered and is kept out of the sapperfile
+}
+
+// Validate checks that value is one of the parameter's choices and matches its pattern
+func (p BrickParameters) Validate(value string) error {
+	if len(p.Choices) > 0 {
+		found := false
+		for _, c := range p.Choices {
+			if c == value {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid value '%s' for parameter %s. Must be one of %s", value, p.Name, strings.Join(p.Choices, ", "))
+		}
+	}
+	if p.Pattern != "" {
+		exp, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s' of parameter %s: %v", p.Pattern, p.Name, err)
+		}
+		if !exp.MatchString(value) {
+			return fmt.Errorf("invalid value '%s' for parameter %s. Must match %s", value, p.Name, p.Pattern)
+		}
+	}
+	return nil
 }
 
 type BrickKind int
@@ -26,14 +58,36 @@ const (
 var BrickKinds = []BrickKind{Template, Extension, Helper}
 
 type Brick struct {
-	Id           string
-	Description  string
-	Version      string
-	Kind         BrickKind
-	Parameters   []BrickParameters
-	Dependencies []string
-	BasePath     string
-	Files        []string
+	ManifestVersion int `yaml:"manifestVersion"`
+	Id              string
+	Description     string
+	Version         string
+	Kind            BrickKind
+	Parameters      []BrickParameters
+	Dependencies    []string
+	BasePath        string
+	Files           []string
+}
+
+// BrickManifestSchema describes the format of a brick's manifest.yaml
+type BrickManifestSchema interface {
+	JSONSchema() ([]byte, error)
+}
+
+// BrickManifestWriter writes the manifest.yaml of a brick into a folder
+type BrickManifestWriter interface {
+	WriteManifest(b Brick, dir string) error
+}
+
+// BrickManifestError describes a manifest.yaml that could not be read
+type BrickManifestError struct {
+	Path string
+	Id   string //id of the brick if it could be read from the manifest
+	Err  error
+}
+
+func (e BrickManifestError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
 }
 
 type BrickDBFactory interface {
@@ -43,11 +97,21 @@ type BrickDBFactory interface {
 
 type BrickDB interface {
 	Bricks(kind BrickKind) []Brick
-	Brick(id string) (Brick, error)
-	Update() error
+	Brick(id string) (Brick, error)                                       //highest version of the brick
+	BrickVersions(id string) []Brick                                      //all versions of the brick in ascending order
+	BrickMatching(id string, constraint VersionConstraint) (Brick, error) //highest version of the brick that matches the constraint
+	InvalidManifests() []BrickManifestError                               //manifests that have been skipped because they could not be read
+	Update(output io.Writer) error                                        //pulls the bricks from their source and reports the progress to output
+	LastUpdate() time.Time                                                //point in time at which the bricks have been updated from their source
 	IsModified() (bool, string)
 }
 
+// AggregatedBrickDB is a brick db that combines the brick dbs of several remotes
+type AggregatedBrickDB interface {
+	BrickDB
+	RemoteBrickDBs() []BrickDB //brick dbs of the remotes in the order of the remotes
+}
+
 var (
 	brickKindMap = map[string]BrickKind{
 		"template":  BrickKind(Template),
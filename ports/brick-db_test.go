@@ -79,3 +79,27 @@ func TestBrickKind_UnmarshalYAML(t *testing.T) {
 		})
 	}
 }
+
+func TestBrickParameters_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter BrickParameters
+		value     string
+		wantErr   bool
+	}{
+		{name: "no constraints", parameter: BrickParameters{Name: "P"}, value: "anything", wantErr: false},
+		{name: "valid choice", parameter: BrickParameters{Name: "P", Choices: []string{"postgres", "mysql"}}, value: "mysql", wantErr: false},
+		{name: "invalid choice", parameter: BrickParameters{Name: "P", Choices: []string{"postgres", "mysql"}}, value: "sqlite", wantErr: true},
+		{name: "matching pattern", parameter: BrickParameters{Name: "P", Pattern: "[0-9]+"}, value: "8080", wantErr: false},
+		{name: "pattern must match completely", parameter: BrickParameters{Name: "P", Pattern: "[0-9]+"}, value: "8080a", wantErr: true},
+		{name: "alternatives in pattern match completely", parameter: BrickParameters{Name: "P", Pattern: "a|b"}, value: "ab", wantErr: true},
+		{name: "invalid pattern", parameter: BrickParameters{Name: "P", Pattern: "[0-9"}, value: "8080", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parameter.Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("BrickParameters.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package ports
+
+type BrickPackage struct {
+	Id      string
+	Version string
+	Digest  string
+	Path    string
+}
+
+type BrickPackager interface {
+	Pack(b Brick, packagePath string) error
+	Unpack(packagePath string, targetDir string) error
+}
@@ -0,0 +1,36 @@
+package ports
+
+// ConfigurationSource describes where a configuration value comes from
+type ConfigurationSource string
+
+const (
+	UserConfiguration    ConfigurationSource = "user"    //the user's config.yaml
+	ProjectConfiguration ConfigurationSource = "project" //the project's .sapper.yaml
+	EnvConfiguration     ConfigurationSource = "env"     //an environment variable
+	FlagConfiguration    ConfigurationSource = "flag"    //a command line flag
+	DefaultConfiguration ConfigurationSource = "default"
+)
+
+type ConfigurationValue struct {
+	Key    string              `json:"key"` //dotted key, e.g. Parameters.CONTAINER_REGISTRY
+	Value  string              `json:"value"`
+	Source ConfigurationSource `json:"source"`
+}
+
+// ConfigurationEditor provides access to the effective configuration by dotted keys
+type ConfigurationEditor interface {
+	Save() error
+	ConfigPath() string
+	Values() []ConfigurationValue
+	SetValue(key string, value string) error
+	UnsetValue(key string) error
+}
+
+type ConfigApi interface {
+	Parameters() map[string]string
+	List() []ConfigurationValue
+	Get(key string) (ConfigurationValue, error)
+	Set(key string, value string) error
+	Unset(key string) error
+	Path() string
+}
@@ -1,8 +1,40 @@
 package ports
 
+import (
+	"path"
+	"time"
+)
+
+// BrickPin specifies that the bricks whose ids match a pattern (e.g. handler-http or handler-*) are taken from a specific remote
+type BrickPin struct {
+	Brick  string
+	Remote string
+}
+
+func (p BrickPin) Matches(brickId string) bool {
+	matches, err := path.Match(p.Brick, brickId)
+	return err == nil && matches
+}
+
+// MatchingPin returns the first pin that matches the brick
+func MatchingPin(pins []BrickPin, brickId string) (BrickPin, bool) {
+	for _, p := range pins {
+		if p.Matches(brickId) {
+			return p, true
+		}
+	}
+	return BrickPin{}, false
+}
+
 type Configuration interface {
 	Save() error
 	DefaultRemotesDir() string
 	Remotes() []Remote
 	UpdateRemotes(remotes []Remote)
+	ProjectRemotes() []Remote //remotes of the project configuration. They are part of Remotes, but cannot be changed
+	Pins() []BrickPin
+	UpdatePins(pins []BrickPin)
+	Parameters() map[string]string //default values of brick parameters (e.g. a container registry)
+	UpdateParameters(parameters map[string]string)
+	RemoteUpdateTTL() time.Duration //remotes older than this are updated automatically. 0 disables the automatic update
 }
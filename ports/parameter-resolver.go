@@ -1,5 +1,56 @@
 package ports
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ParameterResolver interface {
 	Resolve(name string, defaultValue string) string
 }
+
+// ParameterValidator is implemented by parameter resolvers that can detect parameters that are not declared by any brick (e.g. typos in a parameter file)
+type ParameterValidator interface {
+	Validate(declared []BrickParameters) error
+}
+
+// ParameterRequest is a parameter declared by a brick along with its value. The value is empty if it has not been resolved yet.
+type ParameterRequest struct {
+	Brick     string
+	Parameter BrickParameters
+	Value     string
+}
+
+// ParameterPrompter is implemented by parameter resolvers that ask the user for all parameters at once (e.g. to group them by brick
+// and let the user review the values before anything is written)
+type ParameterPrompter interface {
+	// Prompt asks for the values of the requests without a value and returns the values of all requests that have a value afterwards
+	Prompt(requests []ParameterRequest) (map[string]string, error)
+}
+
+// MissingParameter is a parameter whose value could not be resolved
+type MissingParameter struct {
+	Name        string
+	Description string
+	Bricks      []string //ids of the bricks declaring the parameter
+}
+
+// MissingParametersError lists all parameters whose values could not be resolved
+type MissingParametersError struct {
+	Parameters []MissingParameter
+}
+
+func (e MissingParametersError) Error() string {
+	lines := []string{}
+	for _, p := range e.Parameters {
+		line := "  " + p.Name
+		if len(p.Bricks) > 0 {
+			line += fmt.Sprintf(" (brick %s)", strings.Join(p.Bricks, ", "))
+		}
+		if p.Description != "" {
+			line += ": " + p.Description
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf("unable to resolve values for %d parameter(s):\n%s", len(e.Parameters), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,18 @@
+package ports
+
+// ProfileConfiguration manages named profiles of the configuration, each with its own remotes and defaults
+type ProfileConfiguration interface {
+	Save() error
+	ActiveProfile() string
+	ProfileNames() []string
+	UseProfile(name string) error
+	AddProfile(name string) error
+	RemoveProfile(name string) error
+}
+
+type ProfileApi interface {
+	List() (profiles []string, active string)
+	Use(name string) error
+	Add(name string) error
+	Remove(name string) error
+}
@@ -1,5 +1,14 @@
 package ports
 
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
 type RemoteKind int
 
 const (
@@ -13,10 +22,36 @@ type Remote struct {
 	Src  string //folder path for file system remotes and url for git repositories
 }
 
+var invalidDirCharsExp = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CloneDir returns the folder in remotesDir that contains the clone of a git url. The folder is derived from the url so that all
+// remotes with the same url (e.g. in different profiles) share their clone.
+func CloneDir(remotesDir string, url string) string {
+	name := url
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+len("://"):]
+	}
+	if i := strings.Index(name, "@"); i >= 0 && !strings.Contains(name[:i], "/") { //skip credentials
+		name = name[i+1:]
+	}
+	name = strings.Trim(invalidDirCharsExp.ReplaceAllString(strings.TrimSuffix(name, ".git"), "_"), "._")
+	sum := sha256.Sum256([]byte(url)) //different urls may result in the same name
+	return filepath.Join(remotesDir, fmt.Sprintf("%s-%x", name, sum[:4]))
+}
+
 type RemoteApi interface {
 	Add(name string, src string, position int) error
 	Remove(name string) error
-	Update(name string) error
+	Rename(name string, newName string) error
+	Move(name string, position int) error
+	SetUrl(name string, src string) error
+	Update(name string, writer io.Writer) error
+	UpdateAll(writer io.Writer) error
+	UpdateStale(writer io.Writer) error
 	Upgrade(name string) error
+	Verify(name string, writer io.Writer) error
+	Pin(pattern string, name string) error
+	Unpin(pattern string) error
+	Pins() []BrickPin
 	List() []Remote
 }
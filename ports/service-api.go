@@ -8,6 +8,9 @@ import (
 type ServiceApi interface {
 	Add(templateName string, parentDir string, parameterResolver ParameterResolver) (Service, error)
 	Describe(path string, writer io.Writer) error
+	VerifyBricks(path string, writer io.Writer) error
+	Vendor(path string, writer io.Writer) error
+	SetParameter(path string, name string, value string, writer io.Writer) error
 	Upgrade(path string, keepMajorVersion bool) error
 	Build(path string) (string, error)
 	Test(path string) error
@@ -5,14 +5,24 @@ import "io"
 type BrickDependency struct {
 	Id      string
 	Version string
+	Digest  string `yaml:",omitempty"`
 }
 
+// DefaultSecretReference refers to the file that keeps the values of secret parameters unless another reference is given
+const DefaultSecretReference = "file:.sapper/secrets.yaml"
+
+// EnvSecretReferencePrefix starts the references of secret parameters whose values are read from an environment variable
+const EnvSecretReferencePrefix = "env:"
+
 type Service struct {
 	Id           string
 	Path         string `yaml:"-"`
 	BrickIds     []BrickDependency
 	Dependencies []PackageDependency `yaml:"-"`
 	Parameters   map[string]string
+	// Secrets maps the names of secret parameters to references to their values, i.e. 'env:VARIABLE' or 'file:PATH' with a path
+	// relative to the service. The values of secret parameters are part of Parameters, but are never written to the sapperfile.
+	Secrets map[string]string `yaml:",omitempty"`
 }
 
 type ServicePersistence interface {
@@ -0,0 +1,104 @@
+package ports
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type versionComparator struct {
+	operator string
+	version  SemanticVersion
+}
+
+func (vc versionComparator) matches(v SemanticVersion) bool {
+	switch vc.operator {
+	case ">":
+		return Less(vc.version, v)
+	case ">=":
+		return !Less(v, vc.version)
+	case "<":
+		return Less(v, vc.version)
+	case "<=":
+		return !Less(vc.version, v)
+	default:
+		return !Less(v, vc.version) && !Less(vc.version, v)
+	}
+}
+
+// VersionConstraint restricts the versions of a brick. The zero value matches any version.
+type VersionConstraint struct {
+	raw         string
+	comparators []versionComparator
+}
+
+var comparatorExp = regexp.MustCompile(`^(\^|~|>=|<=|>|<|=)?(.+)$`)
+
+// ParseVersionConstraint parses constraints such as '1.2.3', '^1.2.3', '~1.2.3', or '>=1.0.0 <2.0.0'.
+// All space separated comparators must be fulfilled. An empty constraint or '*' matches any version.
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	c := VersionConstraint{raw: s}
+	for _, field := range strings.Fields(s) {
+		if field == "*" {
+			continue
+		}
+		m := comparatorExp.FindStringSubmatch(field)
+		v, err := ParseSemanticVersion(m[2])
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %s: %v", s, err)
+		}
+		switch m[1] {
+		case "^": //compatible with version, i.e. same major version (or same minor version for 0.x.y)
+			upper := SemanticVersion{Major: v.Major + 1}
+			if v.Major == 0 {
+				upper = SemanticVersion{Minor: v.Minor + 1}
+			}
+			c.comparators = append(c.comparators, versionComparator{operator: ">=", version: v}, versionComparator{operator: "<", version: upper})
+		case "~": //approximately equivalent to version, i.e. same minor version
+			upper := SemanticVersion{Major: v.Major, Minor: v.Minor + 1}
+			c.comparators = append(c.comparators, versionComparator{operator: ">=", version: v}, versionComparator{operator: "<", version: upper})
+		default:
+			c.comparators = append(c.comparators, versionComparator{operator: m[1], version: v})
+		}
+	}
+	return c, nil
+}
+
+func (c VersionConstraint) String() string {
+	if len(c.comparators) == 0 {
+		return "*"
+	}
+	return c.raw
+}
+
+// Matches checks if a version fulfills the constraint. Versions that don't follow semantic versioning only match the empty constraint.
+func (c VersionConstraint) Matches(version string) bool {
+	if len(c.comparators) == 0 {
+		return true
+	}
+	v, err := ParseSemanticVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, vc := range c.comparators {
+		if !vc.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// LessVersion orders version strings. Semantic versions are ordered by precedence.
+// Other versions are ordered lexically and precede all semantic versions.
+func LessVersion(a, b string) bool {
+	va, errA := ParseSemanticVersion(a)
+	vb, errB := ParseSemanticVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return Less(va, vb)
+	case errA != nil && errB != nil:
+		return a < b
+	default:
+		return errA != nil
+	}
+}
@@ -0,0 +1,65 @@
+package ports
+
+import "testing"
+
+func TestParseVersionConstraint(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		matches  []string
+		mismatch []string
+		wantErr  bool
+	}{
+		{name: "empty", raw: "", matches: []string{"0.0.1", "2.3.4", "latest"}},
+		{name: "wildcard", raw: "*", matches: []string{"0.0.1", "2.3.4"}},
+		{name: "exact", raw: "1.2.3", matches: []string{"1.2.3", "v1.2.3"}, mismatch: []string{"1.2.4", "1.2.2", "latest"}},
+		{name: "exact with operator", raw: "=1.2.3", matches: []string{"1.2.3"}, mismatch: []string{"1.2.4"}},
+		{name: "caret", raw: "^1.2.3", matches: []string{"1.2.3", "1.9.0"}, mismatch: []string{"1.2.2", "2.0.0"}},
+		{name: "caret major zero", raw: "^0.2.3", matches: []string{"0.2.3", "0.2.9"}, mismatch: []string{"0.3.0", "1.0.0"}},
+		{name: "tilde", raw: "~1.2.3", matches: []string{"1.2.3", "1.2.9"}, mismatch: []string{"1.3.0", "1.2.2"}},
+		{name: "range", raw: ">=1.0.0 <2.0.0", matches: []string{"1.0.0", "1.99.99"}, mismatch: []string{"0.9.9", "2.0.0"}},
+		{name: "exclusive range", raw: ">1.0.0 <=2.0.0", matches: []string{"1.0.1", "2.0.0"}, mismatch: []string{"1.0.0", "2.0.1"}},
+		{name: "invalid", raw: "^abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseVersionConstraint(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseVersionConstraint() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for _, v := range tt.matches {
+				if !c.Matches(v) {
+					t.Errorf("VersionConstraint(%s).Matches(%s) = false, want true", tt.raw, v)
+				}
+			}
+			for _, v := range tt.mismatch {
+				if c.Matches(v) {
+					t.Errorf("VersionConstraint(%s).Matches(%s) = true, want false", tt.raw, v)
+				}
+			}
+		})
+	}
+}
+
+func TestLessVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "semantic versions", a: "1.2.3", b: "1.10.0", want: true},
+		{name: "semantic versions reversed", a: "1.10.0", b: "1.2.3", want: false},
+		{name: "equal", a: "1.0.0", b: "1.0.0", want: false},
+		{name: "non semantic before semantic", a: "latest", b: "0.0.1", want: true},
+		{name: "semantic after non semantic", a: "0.0.1", b: "latest", want: false},
+		{name: "non semantic lexically", a: "alpha", b: "beta", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LessVersion(tt.a, tt.b); got != tt.want {
+				t.Errorf("LessVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package ports
+
+type FileChangeKind int
+
+const (
+	FileAdded FileChangeKind = iota
+	FileModified
+	FileDeleted
+)
+
+// FileChange describes a file that has changed since a given revision. The path is relative to the inspected folder.
+type FileChange struct {
+	Path string
+	Kind FileChangeKind
+}
+
+type VersionControl interface {
+	ChangedFiles(path string, since string) ([]FileChange, error)
+	FileContent(path string, revision string, file string) (string, error)
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/seboste/sapper/ports"
+)
+
+func cellSymbol(status ports.BrickTestStatus) string {
+	switch status {
+	case ports.BrickTestPassed:
+		return "ok"
+	case ports.BrickTestSetupFailed:
+		return "SETUP"
+	case ports.BrickTestBuildFailed:
+		return "BUILD"
+	case ports.BrickTestTestFailed:
+		return "TEST"
+	}
+	return "?"
+}
+
+func resultKey(a string, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
+
+// WriteBrickTestMatrix writes the compatibility matrix of a brick test report as text. The diagonal contains the results of the single bricks.
+func WriteBrickTestMatrix(report ports.BrickTestReport, w io.Writer) error {
+	results := map[string]ports.BrickTestResult{}
+	for _, r := range report.Results {
+		switch len(r.BrickIds) {
+		case 1:
+			results[resultKey(r.BrickIds[0], r.BrickIds[0])] = r
+		case 2:
+			results[resultKey(r.BrickIds[0], r.BrickIds[1])] = r
+		}
+	}
+
+	fmt.Fprintf(w, "template: %s\n", report.Template)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "\t%s\n", strings.Join(report.BrickIds, "\t"))
+	for _, row := range report.BrickIds {
+		cells := []string{row}
+		for _, column := range report.BrickIds {
+			if r, ok := results[resultKey(row, column)]; ok {
+				cells = append(cells, cellSymbol(r.Status))
+			} else {
+				cells = append(cells, "-")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range report.Results {
+		if r.Status != ports.BrickTestPassed {
+			fmt.Fprintf(w, "%s: %s: %s\n", strings.Join(r.BrickIds, " + "), r.Status, r.Message)
+		}
+	}
+	return nil
+}
+
+type jsonBrickTestResult struct {
+	Bricks          []string `json:"bricks"`
+	Status          string   `json:"status"`
+	Message         string   `json:"message,omitempty"`
+	DurationSeconds float64  `json:"durationSeconds"`
+}
+
+type jsonBrickTestReport struct {
+	Template string                `json:"template"`
+	Bricks   []string              `json:"bricks"`
+	Results  []jsonBrickTestResult `json:"results"`
+}
+
+// WriteBrickTestJSON writes a brick test report as JSON
+func WriteBrickTestJSON(report ports.BrickTestReport, w io.Writer) error {
+	jr := jsonBrickTestReport{Template: report.Template, Bricks: report.BrickIds, Results: []jsonBrickTestResult{}}
+	for _, r := range report.Results {
+		jr.Results = append(jr.Results, jsonBrickTestResult{
+			Bricks:          r.BrickIds,
+			Status:          r.Status.String(),
+			Message:         r.Message,
+			DurationSeconds: r.Duration.Seconds(),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jr)
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// WriteBrickTestJUnit writes a brick test report as JUnit XML so that it can be consumed by CI systems
+func WriteBrickTestJUnit(report ports.BrickTestReport, w io.Writer) error {
+	suite := junitTestSuite{Name: "sapper brick test " + report.Template}
+	totalSeconds := 0.0
+	for _, r := range report.Results {
+		tc := junitTestCase{
+			Name:      strings.Join(r.BrickIds, " + "),
+			ClassName: report.Template,
+			Time:      fmt.Sprintf("%.3f", r.Duration.Seconds()),
+		}
+		if r.Status != ports.BrickTestPassed {
+			tc.Failure = &junitFailure{Message: r.Status.String(), Type: strings.ReplaceAll(r.Status.String(), " ", "_"), Text: r.Message}
+			suite.Failures++
+		}
+		totalSeconds += r.Duration.Seconds()
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+	suite.Time = fmt.Sprintf("%.3f", totalSeconds)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seboste/sapper/ports"
+)
+
+var testReport = ports.BrickTestReport{
+	Template: "base",
+	BrickIds: []string{"a", "b"},
+	Results: []ports.BrickTestResult{
+		{BrickIds: []string{"a"}, Status: ports.BrickTestPassed, Duration: time.Second},
+		{BrickIds: []string{"b"}, Status: ports.BrickTestPassed, Duration: time.Second},
+		{BrickIds: []string{"a", "b"}, Status: ports.BrickTestBuildFailed, Message: "exit status 2", Duration: 2 * time.Second},
+	},
+}
+
+func TestWriteBrickTestMatrix(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteBrickTestMatrix(testReport, &buffer); err != nil {
+		t.Fatalf("WriteBrickTestMatrix() error = %v", err)
+	}
+	want := "template: base\n" +
+		"   a      b\n" +
+		"a  ok     BUILD\n" +
+		"b  BUILD  ok\n" +
+		"a + b: build failed: exit status 2\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("WriteBrickTestMatrix() = %q, want %q", got, want)
+	}
+}
+
+// compareWithGoldenFile compares the output of a writer with the expected output in testdata
+func compareWithGoldenFile(t *testing.T, got string, goldenFile string) {
+	want, err := ioutil.ReadFile(filepath.Join("testdata", goldenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output = %s, want %s", got, string(want))
+	}
+}
+
+func TestWriteBrickTestJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteBrickTestJSON(testReport, &buffer); err != nil {
+		t.Fatalf("WriteBrickTestJSON() error = %v", err)
+	}
+	compareWithGoldenFile(t, buffer.String(), "brick-test-report.json")
+}
+
+func TestWriteBrickTestJUnit(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteBrickTestJUnit(testReport, &buffer); err != nil {
+		t.Fatalf("WriteBrickTestJUnit() error = %v", err)
+	}
+	compareWithGoldenFile(t, buffer.String(), "brick-test-report.xml")
+}
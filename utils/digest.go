@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const digestPrefix = "sha256:"
+
+// DigestFiles computes a content digest over the given files (relative to basePath).
+// The digest covers both the relative file paths and their content and is independent of the order of files.
+func DigestFiles(basePath string, files []string) (string, error) {
+	sortedFiles := make([]string, len(files))
+	copy(sortedFiles, files)
+	sort.Strings(sortedFiles)
+
+	h := sha256.New()
+	for _, f := range sortedFiles {
+		fh, err := digestFile(filepath.Join(basePath, f))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", filepath.ToSlash(f), fh)
+	}
+	return fmt.Sprintf("%s%x", digestPrefix, h.Sum(nil)), nil
+}
+
+func digestFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
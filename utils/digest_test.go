@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDigestFiles(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "digestTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	os.MkdirAll(filepath.Join(tempDir, "b"), 0777)
+	ioutil.WriteFile(filepath.Join(tempDir, "a"), []byte("content a"), 0666)
+	ioutil.WriteFile(filepath.Join(tempDir, "b", "c"), []byte("content c"), 0666)
+
+	reference, err := DigestFiles(tempDir, []string{"a", "b/c"})
+	if err != nil {
+		t.Fatalf("DigestFiles() error = %v", err)
+	}
+	if !strings.HasPrefix(reference, "sha256:") {
+		t.Errorf("DigestFiles() = %v, want prefix sha256:", reference)
+	}
+
+	tests := []struct {
+		name      string
+		files     []string
+		wantEqual bool
+		wantErr   bool
+	}{
+		{name: "same files", files: []string{"a", "b/c"}, wantEqual: true},
+		{name: "different order", files: []string{"b/c", "a"}, wantEqual: true},
+		{name: "subset", files: []string{"a"}, wantEqual: false},
+		{name: "missing file", files: []string{"a", "d"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DigestFiles(tempDir, tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DigestFiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (got == reference) != tt.wantEqual {
+				t.Errorf("DigestFiles() = %v, reference %v, wantEqual %v", got, reference, tt.wantEqual)
+			}
+		})
+	}
+}
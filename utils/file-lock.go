@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockTimeout is the maximum time to wait for a lock that is held by another process
+var LockTimeout = 5 * time.Minute
+
+// lockPollInterval is the time between two attempts to acquire a lock
+var lockPollInterval = 100 * time.Millisecond
+
+// unknownHolderGracePeriod is the time after which a lock file without a valid process id is considered stale (e.g. because
+// its process crashed right after creating it)
+var unknownHolderGracePeriod = 10 * time.Second
+
+// isStale returns true if the process holding the lock does no longer exist
+func isStale(path string, holder int) bool {
+	if holder != 0 {
+		return !processExists(holder)
+	}
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > unknownHolderGracePeriod
+}
+
+// lockHolder returns the id of the process that holds the lock or 0 if unknown
+func lockHolder(path string) int {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
+
+func tryLock(path string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+	return true, err
+}
+
+// removeStaleLock removes the lock if it is still held by the stale holder. Processes that have found the same stale lock
+// take turns by means of a takeover lock so that none of them removes the lock that another one has acquired in the meantime.
+// It returns false if another process is taking over the lock.
+func removeStaleLock(path string, holder int) (bool, error) {
+	takeoverPath := path + ".takeover"
+	ok, err := tryLock(takeoverPath)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		if isStale(takeoverPath, lockHolder(takeoverPath)) {
+			os.Remove(takeoverPath)
+		}
+		return false, nil
+	}
+	defer os.Remove(takeoverPath)
+
+	if lockHolder(path) != holder || !isStale(path, holder) {
+		return true, nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockFile acquires an advisory lock that is shared between processes by creating the lock file exclusively. If the lock is held
+// by another process, a message is written to progress and LockFile waits until the lock is released or LockTimeout has elapsed.
+// Locks of processes that do no longer exist are taken over. The returned function releases the lock.
+func LockFile(path string, progress io.Writer) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(LockTimeout)
+	reportedHolder := -1
+	for {
+		ok, err := tryLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return func() { os.Remove(path) }, nil
+		}
+
+		holder := lockHolder(path)
+		if isStale(path, holder) {
+			removed, err := removeStaleLock(path, holder)
+			if err != nil {
+				return nil, err
+			}
+			if removed {
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout after %v waiting for lock %s held by pid %d. Remove the file if that process does no longer exist", LockTimeout, path, holder)
+		}
+		if holder != reportedHolder {
+			fmt.Fprintf(progress, "waiting for lock %s held by pid %d...\n", path, holder)
+			reportedHolder = holder
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// WriteFileAtomically writes the data to a temporary file that replaces the file afterwards so that readers never see a partially written file
+func WriteFileAtomically(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //no effect after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
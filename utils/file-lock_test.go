@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "lockTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	savedTimeout, savedInterval := LockTimeout, lockPollInterval
+	defer func() { LockTimeout, lockPollInterval = savedTimeout, savedInterval }()
+	LockTimeout, lockPollInterval = 300*time.Millisecond, 10*time.Millisecond
+
+	alive := os.Getppid() //the parent process (go test) is running
+	stale := 2147483646   //pid that does not exist
+
+	tests := []struct {
+		name         string
+		holder       int //pid that holds the lock. 0 for unlocked, -1 for an invalid lock file
+		age          time.Duration
+		releaseAfter time.Duration
+		wantErr      bool
+		wantMessage  string
+	}{
+		{name: "unlocked", holder: 0, wantErr: false},
+		{name: "held by running process", holder: alive, wantErr: true, wantMessage: "held by pid"},
+		{name: "released while waiting", holder: alive, releaseAfter: 50 * time.Millisecond, wantErr: false, wantMessage: "waiting for lock"},
+		{name: "stale lock", holder: stale, wantErr: false},
+		{name: "invalid lock file", holder: -1, wantErr: true},
+		{name: "old invalid lock file", holder: -1, age: time.Minute, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "sub", "test.lock")
+			os.RemoveAll(filepath.Dir(path))
+			if tt.holder != 0 {
+				os.MkdirAll(filepath.Dir(path), os.ModePerm)
+				ioutil.WriteFile(path, []byte(strconv.Itoa(tt.holder)+"\n"), 0644)
+				modTime := time.Now().Add(-tt.age)
+				os.Chtimes(path, modTime, modTime)
+			}
+			if releaseAfter := tt.releaseAfter; releaseAfter > 0 {
+				released := make(chan struct{})
+				defer func() { <-released }()
+				go func() {
+					defer close(released)
+					time.Sleep(releaseAfter)
+					os.Remove(path)
+				}()
+			}
+
+			progress := bytes.Buffer{}
+			unlock, err := LockFile(path, &progress)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LockFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantMessage != "" && !strings.Contains(progress.String()+fmt.Sprint(err), tt.wantMessage) {
+				t.Errorf("LockFile() progress = %v, error = %v, want message %v", progress.String(), err, tt.wantMessage)
+			}
+			if err != nil {
+				return
+			}
+			if holder := lockHolder(path); holder != os.Getpid() {
+				t.Errorf("LockFile() lock is held by %d, want %d", holder, os.Getpid())
+			}
+			unlock()
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("LockFile() lock has not been released")
+			}
+		})
+	}
+}
+
+func Test_removeStaleLock(t *testing.T) {
+	alive := os.Getppid() //the parent process (go test) is running
+	stale := 2147483646   //pid that does not exist
+
+	tests := []struct {
+		name           string
+		holder         int //pid that holds the lock now
+		takeoverHolder int //pid that is taking over the lock. 0 if none
+		wantRemoved    bool
+		wantLock       bool
+	}{
+		{name: "still stale", holder: stale, wantRemoved: true, wantLock: false},
+		{name: "acquired by another process in the meantime", holder: alive, wantRemoved: true, wantLock: true},
+		{name: "taken over by another process", holder: stale, takeoverHolder: alive, wantRemoved: false, wantLock: true},
+		{name: "stale takeover", holder: stale, takeoverHolder: stale, wantRemoved: false, wantLock: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.lock")
+			ioutil.WriteFile(path, []byte(strconv.Itoa(tt.holder)+"\n"), 0644)
+			if tt.takeoverHolder != 0 {
+				ioutil.WriteFile(path+".takeover", []byte(strconv.Itoa(tt.takeoverHolder)+"\n"), 0644)
+			}
+
+			removed, err := removeStaleLock(path, stale)
+			if err != nil || removed != tt.wantRemoved {
+				t.Errorf("removeStaleLock() = %v, %v, want %v", removed, err, tt.wantRemoved)
+			}
+			if _, err := os.Stat(path); (err == nil) != tt.wantLock {
+				t.Errorf("removeStaleLock() lock exists = %v, want %v", err == nil, tt.wantLock)
+			}
+			if _, err := os.Stat(path + ".takeover"); (err == nil) != (tt.takeoverHolder == alive) {
+				t.Errorf("removeStaleLock() takeover lock exists = %v, want %v", err == nil, tt.takeoverHolder == alive)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	tempDir, _ := ioutil.TempDir("", "atomicWriteTest*")
+	defer os.RemoveAll(tempDir) // clean up
+
+	path := filepath.Join(tempDir, "config.yaml")
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileAtomically(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFileAtomically() error = %v", err)
+		}
+		if got, _ := ioutil.ReadFile(path); string(got) != content {
+			t.Errorf("WriteFileAtomically() content = %v, want %v", string(got), content)
+		}
+	}
+	if entries, _ := ioutil.ReadDir(tempDir); len(entries) != 1 {
+		t.Errorf("WriteFileAtomically() left %d files, want 1", len(entries))
+	}
+}
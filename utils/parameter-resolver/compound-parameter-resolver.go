@@ -22,4 +22,34 @@ func (cpr CompoundParameterResolver) Resolve(name string, defaultValue string) s
 	return ""
 }
 
+// Validate validates the parameters of all resolvers that support validation
+func (cpr CompoundParameterResolver) Validate(declared []ports.BrickParameters) error {
+	for _, pr := range cpr.resolver {
+		if v, ok := pr.(ports.ParameterValidator); ok {
+			if err := v.Validate(declared); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Prompt delegates to the first resolver that supports prompting. Without such a resolver, no further values are resolved.
+func (cpr CompoundParameterResolver) Prompt(requests []ports.ParameterRequest) (map[string]string, error) {
+	for _, pr := range cpr.resolver {
+		if p, ok := pr.(ports.ParameterPrompter); ok {
+			return p.Prompt(requests)
+		}
+	}
+	values := map[string]string{}
+	for _, r := range requests {
+		if r.Value != "" {
+			values[r.Parameter.Name] = r.Value
+		}
+	}
+	return values, nil
+}
+
 var _ ports.ParameterResolver = CompoundParameterResolver{}
+var _ ports.ParameterValidator = CompoundParameterResolver{}
+var _ ports.ParameterPrompter = CompoundParameterResolver{}
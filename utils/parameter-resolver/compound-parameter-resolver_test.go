@@ -1,6 +1,7 @@
 package parameterResolver
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -110,3 +111,68 @@ func TestCompoundParameterResolver_Resolve(t *testing.T) {
 		})
 	}
 }
+
+type TestValidator struct {
+	TestResolver
+	err error
+}
+
+func (t TestValidator) Validate(declared []ports.BrickParameters) error {
+	return t.err
+}
+
+func TestCompoundParameterResolver_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver []ports.ParameterResolver
+		wantErr  bool
+	}{
+		{name: "no validators", resolver: []ports.ParameterResolver{resolverA, resolverB}, wantErr: false},
+		{name: "valid", resolver: []ports.ParameterResolver{resolverA, TestValidator{}}, wantErr: false},
+		{name: "invalid", resolver: []ports.ParameterResolver{TestValidator{}, TestValidator{err: errors.New("unknown parameter")}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpr := MakeCompoundParameterResolver(tt.resolver)
+			if err := cpr.Validate(nil); (err != nil) != tt.wantErr {
+				t.Errorf("CompoundParameterResolver.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type TestPrompter struct {
+	TestResolver
+}
+
+func (t TestPrompter) Prompt(requests []ports.ParameterRequest) (map[string]string, error) {
+	values := map[string]string{}
+	for _, r := range requests {
+		values[r.Parameter.Name] = t.ReturnValue
+	}
+	return values, nil
+}
+
+func TestCompoundParameterResolver_Prompt(t *testing.T) {
+	requests := []ports.ParameterRequest{{Brick: "b", Parameter: ports.BrickParameters{Name: "x"}, Value: "known"}, {Brick: "b", Parameter: ports.BrickParameters{Name: "y"}}}
+	tests := []struct {
+		name     string
+		resolver []ports.ParameterResolver
+		want     map[string]string
+	}{
+		{name: "no prompter", resolver: []ports.ParameterResolver{resolverA}, want: map[string]string{"x": "known"}},
+		{name: "first prompter", resolver: []ports.ParameterResolver{resolverA, TestPrompter{TestResolver{ReturnValue: "P1"}}, TestPrompter{TestResolver{ReturnValue: "P2"}}}, want: map[string]string{"x": "P1", "y": "P1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpr := MakeCompoundParameterResolver(tt.resolver)
+			got, err := cpr.Prompt(requests)
+			if err != nil {
+				t.Errorf("CompoundParameterResolver.Prompt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompoundParameterResolver.Prompt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
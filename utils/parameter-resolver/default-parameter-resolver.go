@@ -0,0 +1,13 @@
+package parameterResolver
+
+import "github.com/seboste/sapper/ports"
+
+// DefaultParameterResolver resolves each parameter to the default value declared by its brick
+type DefaultParameterResolver struct {
+}
+
+func (r DefaultParameterResolver) Resolve(key string, defaultValue string) string {
+	return defaultValue
+}
+
+var _ ports.ParameterResolver = DefaultParameterResolver{}
@@ -0,0 +1,22 @@
+package parameterResolver
+
+import "testing"
+
+func TestDefaultParameterResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		defaultValue string
+		want         string
+	}{
+		{name: "with default", key: "a", defaultValue: "b", want: "b"},
+		{name: "without default", key: "a", defaultValue: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (DefaultParameterResolver{}).Resolve(tt.key, tt.defaultValue); got != tt.want {
+				t.Errorf("DefaultParameterResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package parameterResolver
+
+import (
+	"os"
+
+	"github.com/seboste/sapper/ports"
+)
+
+// DefaultEnvPrefix is prepended to a parameter's name to get the environment variable holding its value (e.g. SAPPER_PARAM_PORT)
+const DefaultEnvPrefix = "SAPPER_PARAM_"
+
+type EnvParameterResolver struct {
+	prefix string
+}
+
+func MakeEnvParameterResolver(prefix string) EnvParameterResolver {
+	return EnvParameterResolver{prefix: prefix}
+}
+
+func (r EnvParameterResolver) Resolve(key string, defaultValue string) string {
+	return os.Getenv(r.prefix + key)
+}
+
+var _ ports.ParameterResolver = EnvParameterResolver{}
@@ -0,0 +1,68 @@
+package parameterResolver
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestEnvParameterResolver_Resolve(t *testing.T) {
+	type fields struct {
+		prefix string
+	}
+	type args struct {
+		key          string
+		defaultValue string
+	}
+	tests := []struct {
+		name   string
+		env    map[string]string
+		fields fields
+		args   args
+		want   string
+		posix  bool //relies on case sensitive environment variables, which Windows does not have
+	}{
+		{
+			name:   "unknown parameter",
+			env:    map[string]string{"SAPPER_PARAM_PORT": "8081"},
+			fields: fields{prefix: DefaultEnvPrefix},
+			args:   args{key: "HOST"},
+			want:   "",
+		},
+		{
+			name:   "known parameter",
+			env:    map[string]string{"SAPPER_PARAM_PORT": "8081"},
+			fields: fields{prefix: DefaultEnvPrefix},
+			args:   args{key: "PORT", defaultValue: "8080"},
+			want:   "8081",
+		},
+		{
+			name:   "custom prefix",
+			env:    map[string]string{"SAPPER_PARAM_PORT": "8081", "CI_PORT": "9090"},
+			fields: fields{prefix: "CI_"},
+			args:   args{key: "PORT"},
+			want:   "9090",
+		},
+		{
+			name:   "prefix is case sensitive",
+			env:    map[string]string{"sapper_param_PORT": "8081"},
+			fields: fields{prefix: DefaultEnvPrefix},
+			args:   args{key: "PORT"},
+			want:   "",
+			posix:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.posix && runtime.GOOS == "windows" {
+				t.Skip("environment variables are case insensitive on windows")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			r := MakeEnvParameterResolver(tt.fields.prefix)
+			if got := r.Resolve(tt.args.key, tt.args.defaultValue); got != tt.want {
+				t.Errorf("EnvParameterResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
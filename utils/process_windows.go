@@ -0,0 +1,24 @@
+package utils
+
+import "syscall"
+
+// processQueryLimitedInformation is the access right PROCESS_QUERY_LIMITED_INFORMATION, which package syscall does not define
+const processQueryLimitedInformation = 0x1000
+
+// stillActive is the exit code STILL_ACTIVE of a process that has not terminated yet
+const stillActive = 259
+
+// processExists returns false if it is certain that there is no process with the given id. Windows does not support signals,
+// thus the process is opened and its exit code is queried instead.
+func processExists(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return err == syscall.ERROR_ACCESS_DENIED //e.g. a process of another user
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}